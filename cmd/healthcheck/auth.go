@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// hasCredentials reports whether an admin token or basic auth users are
+// configured.
+func (c config) hasCredentials() bool {
+	return c.AdminToken != "" || len(c.AdminUsers) > 0
+}
+
+// requireCredentials rejects requests that carry neither the admin bearer
+// token nor the password of an admin user.
+func (c config) requireCredentials(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.authenticated(r) {
+			if c.AdminToken != "" {
+				w.Header().Add("WWW-Authenticate", `Bearer realm="healthcheck"`)
+			}
+
+			if len(c.AdminUsers) > 0 {
+				w.Header().Add("WWW-Authenticate", `Basic realm="healthcheck"`)
+			}
+
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c config) authenticated(r *http.Request) bool {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return c.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.AdminToken)) == 1
+	}
+
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	expected, known := c.AdminUsers[user]
+
+	return known && subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1
+}
+
+// requireAllowed rejects requests from addresses outside AdminAllow. An empty
+// allowlist admits everyone.
+func (c config) requireAllowed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(c.AdminAllow) > 0 && !c.allowed(r.RemoteAddr) {
+			slog.Warn("rejected admin request", "remote", r.RemoteAddr, "path", r.URL.Path)
+			http.Error(w, "forbidden", http.StatusForbidden)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c config) allowed(remote string) bool {
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	return slices.ContainsFunc(c.AdminAllow, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+}
+
+// parseAllowlist parses a list of IP addresses and CIDR ranges, e.g.
+// "127.0.0.1,10.0.0.0/8".
+func parseAllowlist(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	for _, item := range splitList(value) {
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", item, err)
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", item, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// readUsers reads user:password lines from file, e.g. a mounted Secret.
+// Blank lines and lines starting with # are ignored.
+func readUsers(file string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	users := map[string]string{}
+
+	for number, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, password, ok := strings.Cut(line, ":")
+		if !ok || user == "" || password == "" {
+			return nil, fmt.Errorf("invalid line %d in %s, expected user:password", number+1, file)
+		}
+
+		users[user] = password
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("basic auth file %s has no users", file)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/internal/maintenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminListener(t *testing.T) {
+	newConfig := func() config {
+		return config{
+			AdminListen: "127.0.0.1:9090",
+			AdminToken:  "t0ken",
+			AdminUsers:  map[string]string{"prometheus": "s3cret"},
+			AdminAllow:  []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+			History:     history.NewRing(10),
+			Maintenance: maintenance.New(),
+		}
+	}
+
+	serve := func(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("should not start without an address", func(t *testing.T) {
+		assert.Nil(t, setupAdminServer(config{}))
+	})
+
+	t.Run("should keep admin endpoints off the probe port", func(t *testing.T) {
+		handler := setupServer(newConfig()).Handler
+
+		w := serve(handler, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should accept the bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/history", nil)
+		req.Header.Set("Authorization", "Bearer t0ken")
+
+		w := serve(setupAdminServer(newConfig()).Handler, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should accept basic auth", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+		req.SetBasicAuth("prometheus", "s3cret")
+
+		w := serve(setupAdminServer(newConfig()).Handler, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should reject missing and wrong credentials", func(t *testing.T) {
+		handler := setupAdminServer(newConfig()).Handler
+
+		w := serve(handler, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, []string{`Bearer realm="healthcheck"`, `Basic realm="healthcheck"`}, w.Header().Values("WWW-Authenticate"))
+
+		req := httptest.NewRequest(http.MethodGet, "/history", nil)
+		req.SetBasicAuth("prometheus", "wrong")
+
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, req).Code)
+	})
+
+	t.Run("should reject addresses outside the allowlist", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/history", nil)
+		req.RemoteAddr = "198.51.100.7:4321"
+		req.Header.Set("Authorization", "Bearer t0ken")
+
+		w := serve(setupAdminServer(newConfig()).Handler, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should serve without credentials when none are configured", func(t *testing.T) {
+		cfg := config{AdminListen: "127.0.0.1:9090", History: history.NewRing(10)}
+
+		w := serve(setupAdminServer(cfg).Handler, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestParseAllowlist(t *testing.T) {
+	t.Run("should parse addresses and ranges", func(t *testing.T) {
+		prefixes, err := parseAllowlist("127.0.0.1, 10.1.2.3/8,::1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []netip.Prefix{
+			netip.MustParsePrefix("127.0.0.1/32"),
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("::1/128"),
+		}, prefixes)
+	})
+
+	t.Run("should return error for an invalid entry", func(t *testing.T) {
+		_, err := parseAllowlist("localhost")
+
+		assert.ErrorContains(t, err, `invalid address "localhost"`)
+	})
+}
+
+func TestReadUsers(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		t.Helper()
+
+		file := filepath.Join(t.TempDir(), "users")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+		return file
+	}
+
+	t.Run("should read users and skip comments", func(t *testing.T) {
+		users, err := readUsers(write(t, "# scrapers\nprometheus:s3cret\n\nops:pa:ss\n"))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"prometheus": "s3cret", "ops": "pa:ss"}, users)
+	})
+
+	t.Run("should return error for a malformed line", func(t *testing.T) {
+		_, err := readUsers(write(t, "prometheus\n"))
+
+		assert.ErrorContains(t, err, "invalid line 1")
+	})
+
+	t.Run("should return error for a file without users", func(t *testing.T) {
+		_, err := readUsers(write(t, "# nobody\n"))
+
+		assert.ErrorContains(t, err, "has no users")
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+)
+
+// statusTables returns the configured status tables, the default one when
+// none are.
+func (c config) statusTables() []healthcheck.Table {
+	if len(c.StatusTables) == 0 {
+		return []healthcheck.Table{{Name: mariadb.DefaultTable, Scope: health.ScopeAll}}
+	}
+
+	return c.StatusTables
+}
+
+// errorScope returns the probes a round-trip failure of the given category
+// counts against. An ERROR_SCOPES entry wins; otherwise misconfiguration only
+// fails readiness when MISCONFIG_READINESS_ONLY is set, and everything else
+// fails every probe.
+func (c config) errorScope(category mariadb.ErrorCategory) health.Scope {
+	if scope, ok := c.ErrorScopes[category]; ok {
+		return scope
+	}
+
+	if c.MisconfigReadinessOnly && category.IsMisconfiguration() {
+		return health.ScopeReadiness
+	}
+
+	return health.ScopeAll
+}
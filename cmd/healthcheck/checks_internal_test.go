@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCheck is a health.Checker returning a fixed result.
+type stubCheck struct {
+	result health.Result
+}
+
+func (s stubCheck) Name() string {
+	return s.result.Name
+}
+
+func (s stubCheck) Check(context.Context, health.DB) health.Result {
+	return s.result
+}
+
+// newRoundTripDB returns a mock database expecting a successful round-trip
+// without DELETE.
+func newRoundTripDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT uuid FROM status WHERE uuid = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("any-uuid"))
+
+	return db
+}
+
+func TestHealthHandlerChecks(t *testing.T) {
+	saturated := stubCheck{health.Result{
+		Name:    "connections",
+		Status:  health.StatusUnhealthy,
+		Scope:   health.ScopeReadiness,
+		Message: "99% of max_connections in use (99/100)",
+	}}
+
+	t.Run("should fail readiness on an unhealthy readiness check", func(t *testing.T) {
+		cfg := config{DBInterface: newRoundTripDB(t), Checks: []health.Checker{saturated}, History: history.NewRing(1)}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "connections: 99% of max_connections in use (99/100)", w.Body.String())
+		assert.Equal(t, history.OutcomeFailed, cfg.History.Entries()[0].Outcome)
+	})
+
+	t.Run("should only report degraded to liveness", func(t *testing.T) {
+		cfg := config{DBInterface: newRoundTripDB(t), Checks: []health.Checker{saturated}, History: history.NewRing(1)}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=liveness", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "degraded: connections: 99% of max_connections in use (99/100)", w.Body.String())
+		assert.Equal(t, history.OutcomeDegraded, cfg.History.Entries()[0].Outcome)
+		assert.Equal(t, "connections", cfg.History.Entries()[0].Stages[2].Name)
+	})
+
+	t.Run("should return every result when verbose", func(t *testing.T) {
+		cfg := config{DBInterface: newRoundTripDB(t), Checks: []health.Checker{saturated}}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness&verbose=true", nil))
+
+		var body struct {
+			Status string `json:"status"`
+			Probe  string `json:"probe"`
+			Checks []struct {
+				Name   string `json:"name"`
+				Status string `json:"status"`
+			} `json:"checks"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, "unhealthy", body.Status)
+		assert.Equal(t, "readiness", body.Probe)
+		require.Len(t, body.Checks, 2)
+		assert.Equal(t, "roundtrip", body.Checks[0].Name)
+		assert.Equal(t, "healthy", body.Checks[0].Status)
+		assert.Equal(t, "connections", body.Checks[1].Name)
+	})
+}
+
+func TestStatusTables(t *testing.T) {
+	tables := []healthcheck.Table{
+		{Name: "status_memory", Scope: health.ScopeLiveness},
+		{Name: "status_aria", Scope: health.ScopeReadiness},
+	}
+
+	newConfig := func(t *testing.T) config {
+		t.Helper()
+
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		mock.ExpectExec("INSERT INTO status_memory (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT uuid FROM status_memory WHERE uuid = ?").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("any-uuid"))
+		mock.ExpectExec("INSERT INTO status_aria (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(&mysql.MySQLError{Number: 1021, Message: "Disk full"})
+
+		return config{DBInterface: db, StatusTables: tables, History: history.NewRing(1)}
+	}
+
+	t.Run("should keep liveness green when only the readiness table fails", func(t *testing.T) {
+		cfg := newConfig(t)
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=liveness", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "degraded: roundtrip:status_aria: failed to insert row: disk_full", w.Body.String())
+	})
+
+	t.Run("should fail readiness and name the failing table", func(t *testing.T) {
+		cfg := newConfig(t)
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "roundtrip:status_aria: failed to insert row: disk_full", w.Body.String())
+
+		stages := cfg.History.Entries()[0].Stages
+		require.Len(t, stages, 3)
+		assert.Equal(t, "status_memory.insert", stages[0].Name)
+		assert.Equal(t, "status_aria.insert", stages[2].Name)
+	})
+
+	t.Run("should ping once regardless of the tables", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectPing()
+
+		handler := healthHandler(t, config{DBInterface: db, StatusTables: tables})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?mode=ping", nil))
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
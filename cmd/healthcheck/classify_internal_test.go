@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandlerErrorCategory(t *testing.T) {
+	newConfig := func(t *testing.T, scopes map[mariadb.ErrorCategory]health.Scope) config {
+		t.Helper()
+
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(&mysql.MySQLError{Number: 1290, Message: "The MariaDB server is running with the --read-only option"})
+
+		return config{DBInterface: db, ErrorScopes: scopes}
+	}
+
+	t.Run("should report the category in the body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		healthHandler(t, newConfig(t, nil)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "failed to insert row: read_only", w.Body.String())
+	})
+
+	t.Run("should keep liveness green for a readiness-only category", func(t *testing.T) {
+		scopes := map[mariadb.ErrorCategory]health.Scope{mariadb.CategoryReadOnly: health.ScopeReadiness}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, newConfig(t, scopes)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=liveness", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "degraded: failed to insert row: read_only", w.Body.String())
+	})
+
+	t.Run("should fail readiness for a readiness-only category", func(t *testing.T) {
+		scopes := map[mariadb.ErrorCategory]health.Scope{mariadb.CategoryReadOnly: health.ScopeReadiness}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, newConfig(t, scopes)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "failed to insert row: read_only", w.Body.String())
+	})
+
+	t.Run("should include the category in the verbose output", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		healthHandler(t, newConfig(t, nil)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?verbose=1", nil))
+
+		assert.Contains(t, w.Body.String(), `"category":"read_only"`)
+	})
+}
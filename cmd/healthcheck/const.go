@@ -8,20 +8,140 @@ const (
 	dbPassword = "DB_PASSWORD"
 	dbHost     = "DB_HOST"
 	dbPort     = "DB_PORT"
-	logLevel   = "LOG_LEVEL"
-	deleteRow  = "DELETE_ROW"
-	healthPort = "HEALTH_PORT"
-
-	contextTimeout        = time.Second * 5
-	httpReadTimeout       = time.Second * 5
-	httpReadHeaderTimeout = time.Second * 5
-	httpWriteTimeout      = time.Second * 5
-	httpIdleTimeout       = time.Second * 30
-	shutdownTimeout       = time.Second * 5
-
-	defaultDBUser   = "healthcheck"
-	defaultDBHost   = "127.0.0.1"
-	defaultDBPort   = "3306"
-	defaultDBName   = "healthcheck"
-	defaultHTTPPort = 8080
+
+	dbConnectTimeout  = "DB_CONNECT_TIMEOUT"
+	dbMaxOpenConns    = "DB_MAX_OPEN_CONNS"
+	dbMaxIdleConns    = "DB_MAX_IDLE_CONNS"
+	dbConnMaxLifetime = "DB_CONN_MAX_LIFETIME"
+	dbConnMaxIdleTime = "DB_CONN_MAX_IDLE_TIME"
+
+	checkTimeout           = "CHECK_TIMEOUT"
+	stageTimeout           = "STAGE_TIMEOUT"
+	httpReadTimeout        = "HTTP_READ_TIMEOUT"
+	httpReadHeaderTimeout  = "HTTP_READ_HEADER_TIMEOUT"
+	httpWriteTimeout       = "HTTP_WRITE_TIMEOUT"
+	httpIdleTimeout        = "HTTP_IDLE_TIMEOUT"
+	shutdownTimeout        = "SHUTDOWN_TIMEOUT"
+	terminationGracePeriod = "TERMINATION_GRACE_PERIOD"
+	logLevel               = "LOG_LEVEL"
+	deleteRow              = "DELETE_ROW"
+	healthPort             = "HEALTH_PORT"
+	historySize            = "HISTORY_SIZE"
+
+	healthListen     = "HEALTH_LISTEN"
+	healthSocketMode = "HEALTH_SOCKET_MODE"
+
+	tlsCertFile     = "TLS_CERT_FILE"
+	tlsKeyFile      = "TLS_KEY_FILE"
+	tlsClientCAFile = "TLS_CLIENT_CA_FILE"
+	tlsPort         = "TLS_PORT"
+
+	adminAllow         = "ADMIN_ALLOW"
+	adminBasicAuthFile = "ADMIN_BASIC_AUTH_FILE"
+	adminListen        = "ADMIN_LISTEN"
+	adminToken         = "ADMIN_TOKEN"
+	adminTokenFile     = "ADMIN_TOKEN_FILE"
+	maintenanceTTL     = "MAINTENANCE_TTL"
+	drainPeriod        = "DRAIN_PERIOD"
+
+	checks       = "CHECKS"
+	errorScopes  = "ERROR_SCOPES"
+	statusTables = "STATUS_TABLES"
+
+	misconfigReadinessOnly = "MISCONFIG_READINESS_ONLY"
+
+	minServerVersion = "MIN_SERVER_VERSION"
+
+	connectionsDegradedPercent  = "CONNECTIONS_DEGRADED_PERCENT"
+	connectionsUnhealthyPercent = "CONNECTIONS_UNHEALTHY_PERCENT"
+
+	semiSyncMinClients = "SEMI_SYNC_MIN_CLIENTS"
+
+	innodbHistoryLength        = "INNODB_HISTORY_LENGTH"
+	innodbCheckpointAgePercent = "INNODB_CHECKPOINT_AGE_PERCENT"
+	innodbPendingIO            = "INNODB_PENDING_IO"
+	innodbBufferPoolHitPercent = "INNODB_BUFFER_POOL_HIT_PERCENT"
+	innodbDeadlocks            = "INNODB_DEADLOCKS"
+
+	resourcesOpenFilesPercent        = "RESOURCES_OPEN_FILES_PERCENT"
+	resourcesOpenedTablesPerSecond   = "RESOURCES_OPENED_TABLES_PER_SECOND"
+	resourcesThreadsCreatedPerSecond = "RESOURCES_THREADS_CREATED_PER_SECOND"
+	resourcesTmpDiskTablesPercent    = "RESOURCES_TMP_DISK_TABLES_PERCENT"
+
+	transactionsMaxAge      = "TRANSACTIONS_MAX_AGE"
+	transactionsMaxLockWait = "TRANSACTIONS_MAX_LOCK_WAIT"
+	transactionsShowQueries = "TRANSACTIONS_SHOW_QUERIES"
+
+	metricsEnabled   = "METRICS"
+	metricsStatus    = "METRICS_STATUS"
+	metricsVariables = "METRICS_VARIABLES"
+
+	kubeEvents            = "KUBE_EVENTS"
+	kubeEventsMinInterval = "KUBE_EVENTS_MIN_INTERVAL"
+	podName               = "POD_NAME"
+	podNamespace          = "POD_NAMESPACE"
+	podUID                = "POD_UID"
+	roleLabel             = "ROLE_LABEL"
+	roleLabelInterval     = "ROLE_LABEL_INTERVAL"
+
+	webhookURLs        = "WEBHOOK_URLS"
+	webhookFormat      = "WEBHOOK_FORMAT"
+	webhookSecret      = "WEBHOOK_SECRET"
+	webhookMaxRetries  = "WEBHOOK_MAX_RETRIES"
+	webhookMinInterval = "WEBHOOK_MIN_INTERVAL"
+
+	misconfigLogInterval = time.Minute
+	webhookTimeout       = time.Second * 5
+	webhookRetryBackoff  = time.Second
+	kubeTimeout          = time.Second * 5
+	doctorTimeout        = time.Second * 30
+	certReloadInterval   = time.Second * 30
+
+	defaultDBUser      = "healthcheck"
+	defaultDBHost      = "127.0.0.1"
+	defaultDBPort      = "3306"
+	defaultDBName      = "healthcheck"
+	defaultHTTPPort    = 8080
+	defaultHistorySize = 100
+	defaultSocketMode  = 0o660
+
+	defaultCheckTimeout           = time.Second * 5
+	defaultHTTPReadTimeout        = time.Second * 5
+	defaultHTTPReadHeaderTimeout  = time.Second * 5
+	defaultHTTPWriteTimeout       = time.Second * 10
+	defaultHTTPIdleTimeout        = time.Second * 30
+	defaultShutdownTimeout        = time.Second * 5
+	defaultTerminationGracePeriod = time.Second * 30
+
+	// hungRequestChecks is how many check timeouts a probe may take before
+	// /self reports it as hung.
+	hungRequestChecks = 3
+
+	defaultMaintenanceTTL = time.Hour
+	defaultDrainPeriod    = time.Second * 5
+
+	defaultConnectionsDegradedPercent  = 80
+	defaultConnectionsUnhealthyPercent = 95
+
+	defaultSemiSyncMinClients = 1
+
+	defaultInnoDBHistoryLength        = 1000000
+	defaultInnoDBCheckpointAgePercent = 75
+	defaultInnoDBPendingIO            = 64
+	defaultInnoDBBufferPoolHitPercent = 95
+	defaultInnoDBDeadlocks            = 10
+
+	defaultResourcesOpenFilesPercent        = 80
+	defaultResourcesOpenedTablesPerSecond   = 10
+	defaultResourcesThreadsCreatedPerSecond = 10
+	defaultResourcesTmpDiskTablesPercent    = 25
+
+	defaultTransactionsMaxAge      = time.Minute
+	defaultTransactionsMaxLockWait = time.Second * 30
+
+	defaultRoleLabelInterval     = time.Second * 10
+	defaultKubeEventsMinInterval = time.Minute
+
+	defaultWebhookMaxRetries  = 3
+	defaultWebhookMinInterval = time.Minute
 )
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/doctor"
+)
+
+// commandDoctor is the subcommand that diagnoses the installation instead of
+// serving probes, e.g. kubectl exec mariadb-0 -c healthcheck -- /healthcheck doctor.
+const commandDoctor = "doctor"
+
+// runDoctor diagnoses the configured installation and prints the report to
+// w. It reports whether every diagnostic passed.
+func runDoctor(ctx context.Context, w io.Writer) (bool, error) {
+	config, err := getEnv().parseEnv()
+	if err != nil {
+		return false, fmt.Errorf("failed to parse environment: %w", err)
+	}
+
+	db, err := config.Connection.ConnectDB()
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	tables := make([]string, 0, len(config.statusTables()))
+	for _, table := range config.statusTables() {
+		tables = append(tables, table.Name)
+	}
+
+	findings := doctor.Doctor{
+		Connection: config.Connection,
+		DB:         db,
+		Tables:     tables,
+		Timeout:    config.timeouts().Check,
+	}.Run(ctx)
+
+	if err := doctor.Write(w, findings); err != nil {
+		return false, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return !doctor.Failed(findings), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDoctor(t *testing.T) {
+	t.Run("should return error when the environment is invalid", func(t *testing.T) {
+		t.Setenv(dbPassword, "")
+
+		var out bytes.Buffer
+		ok, err := runDoctor(t.Context(), &out)
+
+		require.Error(t, err)
+		assert.False(t, ok)
+		assert.ErrorContains(t, err, "failed to parse environment")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("should report an unreachable server", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(dbHost, "127.0.0.1")
+		t.Setenv(dbPort, "1")
+
+		var out bytes.Buffer
+		ok, err := runDoctor(t.Context(), &out)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Contains(t, out.String(), "[FAIL] tcp: ")
+		assert.Contains(t, out.String(), "[SKIP] authentication\n")
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+)
+
+// serveDraining answers a probe without touching the database once shutdown
+// has started. Readiness fails so the pod is removed from Service endpoints
+// while liveness stays green until the listener closes. It returns false when
+// the sidecar is not draining.
+func (c config) serveDraining(w http.ResponseWriter, p healthcheck.Probe) bool {
+	if c.Draining == nil || !c.Draining.Load() {
+		return false
+	}
+
+	c.History.Add(history.Entry{
+		Time:    time.Now(),
+		Probe:   string(p),
+		Outcome: history.OutcomeDraining,
+	})
+
+	if p == healthcheck.ProbeReadiness {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	writeBody(w, "shutting down")
+
+	return true
+}
+
+// awaitShutdown blocks until ctx is canceled, then marks the sidecar as
+// draining for period so readiness fails while the listener keeps accepting
+// probes. Only afterwards it triggers a graceful shutdown of server bounded
+// by timeout. Extracted from run() so it can be exercised in unit tests.
+func awaitShutdown(ctx context.Context, server *http.Server, draining *atomic.Bool, period, timeout time.Duration) {
+	<-ctx.Done()
+
+	if period > 0 {
+		slog.Info("draining before shutdown", "period", period)
+
+		draining.Store(true)
+		time.Sleep(period)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("graceful shutdown failed", "error", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeDraining(t *testing.T) {
+	// No DBInterface: any attempt to reach the database would panic.
+	newConfig := func() config {
+		cfg := config{
+			Draining: &atomic.Bool{},
+			History:  history.NewRing(10),
+		}
+		cfg.Draining.Store(true)
+
+		return cfg
+	}
+
+	t.Run("should fail readiness while draining", func(t *testing.T) {
+		cfg := newConfig()
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "shutting down", w.Body.String())
+		require.Len(t, cfg.History.Entries(), 1)
+		assert.Equal(t, history.OutcomeDraining, cfg.History.Entries()[0].Outcome)
+	})
+
+	t.Run("should keep liveness green while draining", func(t *testing.T) {
+		cfg := newConfig()
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=liveness", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "shutting down", w.Body.String())
+	})
+}
+
+func TestAwaitShutdownDrain(t *testing.T) {
+	t.Run("should fail readiness before closing the listener", func(t *testing.T) {
+		cfg := config{Draining: &atomic.Bool{}}
+		srv := setupServer(withHealthCheck(t, cfg))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		go func() { _ = srv.Serve(listener) }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			awaitShutdown(ctx, srv, cfg.Draining, 300*time.Millisecond, defaultShutdownTimeout)
+			close(done)
+		}()
+
+		cancel()
+
+		require.Eventually(t, cfg.Draining.Load, time.Second, 10*time.Millisecond)
+
+		// The listener is still open during the drain period.
+		req, err := http.NewRequestWithContext(
+			t.Context(), http.MethodGet, "http://"+listener.Addr().String()+"/health?probe=readiness", nil,
+		)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, "shutting down", string(body))
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("awaitShutdown did not return after the drain period")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+)
+
+const eventQueueSize = 16
+
+// Event reasons for a failed round-trip whose error category tells more than
+// the stage it failed in.
+var categoryReasons = map[mariadb.ErrorCategory]string{
+	mariadb.CategoryAuthentication:     "AuthenticationFailed",
+	mariadb.CategoryUnknownDatabase:    "UnknownDatabase",
+	mariadb.CategoryPrivileges:         "MissingPrivileges",
+	mariadb.CategoryTooManyConnections: "TooManyConnections",
+	mariadb.CategoryReadOnly:           "ReadOnly",
+	mariadb.CategoryLock:               "LockTimeout",
+	mariadb.CategoryDiskFull:           "DiskFull",
+	mariadb.CategoryUnknownTable:       "StatusTableMissing",
+	mariadb.CategoryNetwork:            "DatabaseUnreachable",
+}
+
+// podEvents records Kubernetes Events on the pod whenever the aggregated
+// state or the reason for it changes, so kubectl describe pod tells why a
+// probe failed. Events are created asynchronously so probes are never blocked
+// by the API server. Like webhook notifications, Events are created at most
+// once per minInterval; changes arriving sooner are coalesced into the latest
+// one. All methods on a nil *podEvents are no-ops.
+type podEvents struct {
+	client      *kube.Client
+	events      chan kube.Event
+	minInterval time.Duration
+
+	mu   sync.Mutex
+	last string
+
+	// created and createdReason describe the last Event sent to the API
+	// server; they are only used by Run.
+	created       time.Time
+	createdReason string
+}
+
+func newPodEvents(client *kube.Client, minInterval time.Duration) *podEvents {
+	return &podEvents{
+		client:      client,
+		events:      make(chan kube.Event, eventQueueSize),
+		minInterval: minInterval,
+	}
+}
+
+// observe queues an Event when status or reason differ from the previous
+// observation. Starting up healthy is not worth an Event.
+func (p *podEvents) observe(status health.Status, reason, message string) {
+	if p == nil {
+		return
+	}
+
+	key := status.String() + "/" + reason
+
+	p.mu.Lock()
+	first, changed := p.last == "", p.last != key
+	p.last = key
+	p.mu.Unlock()
+
+	if !changed || (first && status == health.StatusHealthy) {
+		return
+	}
+
+	event := kube.Event{
+		Type:    kube.EventWarning,
+		Reason:  reason,
+		Message: status.String() + ": " + message,
+		Time:    time.Now(),
+	}
+
+	if status == health.StatusHealthy {
+		event.Type = kube.EventNormal
+		event.Message = "all checks passed"
+	}
+
+	select {
+	case p.events <- event:
+	default:
+		slog.Warn("event queue is full, dropping event", "reason", reason)
+	}
+}
+
+// Run creates queued Events until ctx is canceled.
+func (p *podEvents) Run(ctx context.Context) {
+	if p == nil {
+		return
+	}
+
+	for {
+		var pending kube.Event
+
+		select {
+		case <-ctx.Done():
+			return
+		case pending = <-p.events:
+		}
+
+		pending, ok := p.throttle(ctx, pending)
+		if !ok {
+			return
+		}
+
+		// A flap that settled back where it started is not worth an Event.
+		if pending.Reason == p.createdReason {
+			continue
+		}
+
+		if err := p.client.CreateEvent(ctx, pending); err != nil {
+			slog.Error("failed to create event", "reason", pending.Reason, "error", err)
+			continue
+		}
+
+		p.created, p.createdReason = time.Now(), pending.Reason
+
+		slog.Debug("created event", "type", pending.Type, "reason", pending.Reason)
+	}
+}
+
+// throttle waits until minInterval has passed since the last Event was
+// created, replacing pending with any Event queued meanwhile. It returns
+// false when ctx is canceled.
+func (p *podEvents) throttle(ctx context.Context, pending kube.Event) (kube.Event, bool) {
+	wait := time.Until(p.created.Add(p.minInterval))
+	if wait <= 0 {
+		return pending, true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return pending, false
+		case <-timer.C:
+			return pending, true
+		case pending = <-p.events:
+		}
+	}
+}
+
+// eventReason returns the Event reason for the worst result of a probe, e.g.
+// InsertFailed, ReadOnly or ConnectionsDegraded. err is the error of the
+// round-trip the worst result belongs to.
+func eventReason(worst health.Result, failing bool, err error) string {
+	if !failing {
+		return "Healthy"
+	}
+
+	if !healthcheck.IsRoundTrip(worst.Name) {
+		return camelCase(worst.Name) + camelCase(worst.Status.String())
+	}
+
+	if reason, ok := categoryReasons[mariadb.Classify(err)]; ok {
+		return reason
+	}
+
+	switch {
+	case errors.Is(err, mariadb.ErrInsert):
+		return "InsertFailed"
+	case errors.Is(err, mariadb.ErrSelect):
+		return "SelectFailed"
+	case errors.Is(err, mariadb.ErrScan):
+		return "ScanFailed"
+	case errors.Is(err, mariadb.ErrValidate):
+		return "RowMissing"
+	case errors.Is(err, mariadb.ErrDelete):
+		return "DeleteFailed"
+	case errors.Is(err, mariadb.ErrPing):
+		return "PingFailed"
+	case errors.Is(err, mariadb.ErrRead):
+		return "ReadFailed"
+	default:
+		return "HealthcheckFailed"
+	}
+}
+
+// camelCase turns a check name such as "semi_sync" into "SemiSync".
+func camelCase(name string) string {
+	var b strings.Builder
+
+	for word := range strings.FieldsFuncSeq(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return b.String()
+}
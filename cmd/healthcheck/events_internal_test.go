@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventReason(t *testing.T) {
+	roundTrip := health.Result{Name: healthcheck.CheckRoundTrip, Status: health.StatusUnhealthy}
+
+	for name, tc := range map[string]struct {
+		worst   health.Result
+		failing bool
+		err     error
+		want    string
+	}{
+		"healthy": {want: "Healthy"},
+		"stage": {
+			worst: roundTrip, failing: true,
+			err:  fmt.Errorf("%w: %w", mariadb.ErrInsert, errors.New("boom")),
+			want: "InsertFailed",
+		},
+		"missing row": {
+			worst: roundTrip, failing: true,
+			err:  fmt.Errorf("%w: inserted row not found", mariadb.ErrValidate),
+			want: "RowMissing",
+		},
+		"category": {
+			worst: roundTrip, failing: true,
+			err:  fmt.Errorf("%w: %w", mariadb.ErrInsert, &mysql.MySQLError{Number: 1290}),
+			want: "ReadOnly",
+		},
+		"check": {
+			worst:   health.Result{Name: "connections", Status: health.StatusDegraded},
+			failing: true,
+			want:    "ConnectionsDegraded",
+		},
+	} {
+		assert.Equal(t, tc.want, eventReason(tc.worst, tc.failing, tc.err), name)
+	}
+}
+
+func TestPodEvents(t *testing.T) {
+	queued := func(p *podEvents) []kube.Event {
+		var events []kube.Event
+
+		for {
+			select {
+			case event := <-p.events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	t.Run("should only queue events when the reason changes", func(t *testing.T) {
+		p := newPodEvents(nil, 0)
+
+		p.observe(health.StatusHealthy, "Healthy", "")
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+		p.observe(health.StatusUnhealthy, "ReadOnly", "roundtrip: read only")
+		p.observe(health.StatusHealthy, "Healthy", "")
+
+		events := queued(p)
+
+		require.Len(t, events, 3)
+		assert.Equal(t, "InsertFailed", events[0].Reason)
+		assert.Equal(t, kube.EventWarning, events[0].Type)
+		assert.Equal(t, "unhealthy: roundtrip: failed to insert row", events[0].Message)
+		assert.Equal(t, "ReadOnly", events[1].Reason)
+		assert.Equal(t, "Healthy", events[2].Reason)
+		assert.Equal(t, kube.EventNormal, events[2].Type)
+	})
+
+	t.Run("should create queued events on the pod", func(t *testing.T) {
+		client, bodies := newFakeKube(t, http.StatusCreated)
+		p := newPodEvents(client, 0)
+
+		go p.Run(t.Context())
+
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+
+		require.Eventually(t, func() bool { return len(bodies()) == 1 }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, "InsertFailed", bodies()[0]["reason"])
+	})
+
+	t.Run("should coalesce changes within the minimum interval", func(t *testing.T) {
+		client, bodies := newFakeKube(t, http.StatusCreated)
+		p := newPodEvents(client, 200*time.Millisecond)
+
+		go p.Run(t.Context())
+
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+		require.Eventually(t, func() bool { return len(bodies()) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+		p.observe(health.StatusHealthy, "Healthy", "")
+		p.observe(health.StatusUnhealthy, "ReadOnly", "roundtrip: read only")
+		p.observe(health.StatusHealthy, "Healthy", "")
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+		p.observe(health.StatusHealthy, "Healthy", "")
+
+		require.Eventually(t, func() bool { return len(bodies()) == 2 }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, "Healthy", bodies()[1]["reason"])
+
+		time.Sleep(300 * time.Millisecond)
+		assert.Len(t, bodies(), 2)
+	})
+
+	t.Run("should retry an event the API server rejected", func(t *testing.T) {
+		client, bodies := newFakeKube(t, http.StatusInternalServerError, http.StatusCreated)
+		p := newPodEvents(client, 200*time.Millisecond)
+
+		go p.Run(t.Context())
+
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+		require.Eventually(t, func() bool { return len(bodies()) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+		p.observe(health.StatusHealthy, "Healthy", "")
+		p.observe(health.StatusUnhealthy, "InsertFailed", "roundtrip: failed to insert row")
+
+		require.Eventually(t, func() bool { return len(bodies()) == 3 }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, "Healthy", bodies()[1]["reason"])
+		assert.Equal(t, "InsertFailed", bodies()[2]["reason"])
+	})
+
+	t.Run("should ignore a nil recorder", func(t *testing.T) {
+		var p *podEvents
+
+		assert.NotPanics(t, func() {
+			p.observe(health.StatusUnhealthy, "InsertFailed", "")
+			p.Run(t.Context())
+		})
+	})
+}
@@ -4,9 +4,21 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/richie-tt/mariadb-healthcheck/internal/maintenance"
 	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/internal/metrics"
+	"github.com/richie-tt/mariadb-healthcheck/internal/watchdog"
+	"github.com/richie-tt/mariadb-healthcheck/internal/webhook"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
 )
 
 // or returns value when it is non-empty, otherwise fallback.
@@ -46,8 +58,64 @@ func boolOr(value string, fallback bool) (bool, error) {
 	return b, nil
 }
 
+// floatOr parses value as a float64; returns fallback when value is empty.
+func floatOr(value string, fallback float64) (float64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", value, err)
+	}
+
+	return f, nil
+}
+
+// durationOr parses value as a time.Duration; returns fallback when value is
+// empty.
+func durationOr(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", value)
+	}
+
+	return d, nil
+}
+
+// splitList splits a comma-separated value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
 func getEnv() environment {
 	return environment{
+		AdminAllow:         os.Getenv(adminAllow),
+		AdminBasicAuthFile: os.Getenv(adminBasicAuthFile),
+		AdminListen:        os.Getenv(adminListen),
+		AdminToken:         os.Getenv(adminToken),
+		AdminTokenFile:     os.Getenv(adminTokenFile),
+		Checks:             os.Getenv(checks),
+		Connections: connectionsEnvironment{
+			DegradedPercent:  os.Getenv(connectionsDegradedPercent),
+			UnhealthyPercent: os.Getenv(connectionsUnhealthyPercent),
+		},
 		Connection: mariadb.Connection{
 			Database: os.Getenv(dbName),
 			Driver:   "mysql",
@@ -56,9 +124,83 @@ func getEnv() environment {
 			Port:     os.Getenv(dbPort),
 			User:     os.Getenv(dbUser),
 		},
-		DeleteRow:  os.Getenv(deleteRow),
-		HealthPort: os.Getenv(healthPort),
-		LogLevel:   os.Getenv(logLevel),
+		DeleteRow:        os.Getenv(deleteRow),
+		DrainPeriod:      os.Getenv(drainPeriod),
+		ErrorScopes:      os.Getenv(errorScopes),
+		HealthListen:     os.Getenv(healthListen),
+		HealthPort:       os.Getenv(healthPort),
+		HealthSocketMode: os.Getenv(healthSocketMode),
+		HistorySize:      os.Getenv(historySize),
+		InnoDB: innodbEnvironment{
+			HistoryLength:        os.Getenv(innodbHistoryLength),
+			CheckpointAgePercent: os.Getenv(innodbCheckpointAgePercent),
+			PendingIO:            os.Getenv(innodbPendingIO),
+			BufferPoolHitPercent: os.Getenv(innodbBufferPoolHitPercent),
+			Deadlocks:            os.Getenv(innodbDeadlocks),
+		},
+		Kube: kubeEnvironment{
+			Events:            os.Getenv(kubeEvents),
+			EventsMinInterval: os.Getenv(kubeEventsMinInterval),
+			PodName:           os.Getenv(podName),
+			PodNamespace:      os.Getenv(podNamespace),
+			PodUID:            os.Getenv(podUID),
+			RoleLabel:         os.Getenv(roleLabel),
+			RoleLabelInterval: os.Getenv(roleLabelInterval),
+		},
+		LogLevel:       os.Getenv(logLevel),
+		MaintenanceTTL: os.Getenv(maintenanceTTL),
+		Metrics: metricsEnvironment{
+			Enabled:   os.Getenv(metricsEnabled),
+			Status:    os.Getenv(metricsStatus),
+			Variables: os.Getenv(metricsVariables),
+		},
+		MinVersion: os.Getenv(minServerVersion),
+		Misconfig:  os.Getenv(misconfigReadinessOnly),
+		Pool: poolEnvironment{
+			ConnectTimeout:  os.Getenv(dbConnectTimeout),
+			MaxOpenConns:    os.Getenv(dbMaxOpenConns),
+			MaxIdleConns:    os.Getenv(dbMaxIdleConns),
+			ConnMaxLifetime: os.Getenv(dbConnMaxLifetime),
+			ConnMaxIdleTime: os.Getenv(dbConnMaxIdleTime),
+		},
+		Resources: resourcesEnvironment{
+			OpenFilesPercent:        os.Getenv(resourcesOpenFilesPercent),
+			OpenedTablesPerSecond:   os.Getenv(resourcesOpenedTablesPerSecond),
+			ThreadsCreatedPerSecond: os.Getenv(resourcesThreadsCreatedPerSecond),
+			TmpDiskTablesPercent:    os.Getenv(resourcesTmpDiskTablesPercent),
+		},
+		SemiSync: semiSyncEnvironment{
+			MinClients: os.Getenv(semiSyncMinClients),
+		},
+		StatusTables: os.Getenv(statusTables),
+		Timeouts: timeoutsEnvironment{
+			Check:                  os.Getenv(checkTimeout),
+			Stage:                  os.Getenv(stageTimeout),
+			HTTPRead:               os.Getenv(httpReadTimeout),
+			HTTPReadHeader:         os.Getenv(httpReadHeaderTimeout),
+			HTTPWrite:              os.Getenv(httpWriteTimeout),
+			HTTPIdle:               os.Getenv(httpIdleTimeout),
+			Shutdown:               os.Getenv(shutdownTimeout),
+			TerminationGracePeriod: os.Getenv(terminationGracePeriod),
+		},
+		TLS: tlsEnvironment{
+			CertFile:     os.Getenv(tlsCertFile),
+			KeyFile:      os.Getenv(tlsKeyFile),
+			ClientCAFile: os.Getenv(tlsClientCAFile),
+			Port:         os.Getenv(tlsPort),
+		},
+		Transactions: transactionsEnvironment{
+			MaxAge:      os.Getenv(transactionsMaxAge),
+			MaxLockWait: os.Getenv(transactionsMaxLockWait),
+			ShowQueries: os.Getenv(transactionsShowQueries),
+		},
+		Webhook: webhookEnvironment{
+			URLs:        os.Getenv(webhookURLs),
+			Format:      os.Getenv(webhookFormat),
+			Secret:      os.Getenv(webhookSecret),
+			MaxRetries:  os.Getenv(webhookMaxRetries),
+			MinInterval: os.Getenv(webhookMinInterval),
+		},
 	}
 }
 
@@ -107,6 +249,37 @@ func (e environment) parseEnv() (*config, error) {
 
 	cfg.HealthPort = port
 
+	if e.HealthListen != "" {
+		if e.HealthPort != "" {
+			return nil, fmt.Errorf("failed to parse HealthListen: HEALTH_LISTEN replaces HEALTH_PORT, set only one")
+		}
+
+		addr, err := parseListen(e.HealthListen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HealthListen: %w", err)
+		}
+
+		cfg.HealthListen = addr
+	}
+
+	mode, err := parseFileMode(e.HealthSocketMode, defaultSocketMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HealthSocketMode: %w", err)
+	}
+
+	cfg.HealthSocketMode = mode
+
+	listener, err := e.TLS.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS: %w", err)
+	}
+
+	if listener != nil && cfg.HealthListen == "" && listener.Port == port {
+		return nil, fmt.Errorf("failed to parse TLS: TLS_PORT must differ from HEALTH_PORT")
+	}
+
+	cfg.TLS = listener
+
 	clean, err := boolOr(e.DeleteRow, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DeleteRow: %w", err)
@@ -118,5 +291,545 @@ func (e environment) parseEnv() (*config, error) {
 		slog.Warn("delete row is disabled")
 	}
 
+	size, err := intOr(e.HistorySize, defaultHistorySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HistorySize: %w", err)
+	}
+
+	cfg.History = history.NewRing(size)
+	cfg.Health = health.NewTracker()
+	cfg.Probes = &probeStates{}
+	cfg.Watchdog = watchdog.New()
+
+	notifier, err := e.Webhook.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook: %w", err)
+	}
+
+	cfg.Notifier = notifier
+
+	token, err := e.adminToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admin token: %w", err)
+	}
+
+	cfg.AdminToken = token
+
+	if e.AdminBasicAuthFile != "" {
+		users, err := readUsers(e.AdminBasicAuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read admin users: %w", err)
+		}
+
+		cfg.AdminUsers = users
+	}
+
+	allow, err := parseAllowlist(e.AdminAllow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AdminAllow: %w", err)
+	}
+
+	if len(allow) > 0 && e.AdminListen == "" {
+		return nil, fmt.Errorf("failed to parse AdminAllow: ADMIN_ALLOW requires ADMIN_LISTEN")
+	}
+
+	// A Unix domain socket has no remote address to match, so every request
+	// would be rejected.
+	if len(allow) > 0 && strings.HasPrefix(e.AdminListen, unixScheme) {
+		return nil, fmt.Errorf("failed to parse AdminAllow: ADMIN_ALLOW cannot filter a unix:// ADMIN_LISTEN, use the socket permissions")
+	}
+
+	cfg.AdminAllow = allow
+
+	if e.AdminListen != "" {
+		addr, err := parseListen(e.AdminListen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse AdminListen: %w", err)
+		}
+
+		cfg.AdminListen = addr
+
+		slog.Info("admin endpoints moved to a separate listener", "address", cfg.AdminListen,
+			"authenticated", cfg.hasCredentials(), "allowlist", e.AdminAllow)
+	} else {
+		slog.Warn("admin endpoints are served unauthenticated on the probe listener",
+			"endpoints", "/history, /version, /metrics",
+			"hint", "set ADMIN_LISTEN, e.g. 127.0.0.1:9090, to move them off the probe listener")
+	}
+
+	ttl, err := durationOr(e.MaintenanceTTL, defaultMaintenanceTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MaintenanceTTL: %w", err)
+	}
+
+	cfg.Maintenance = maintenance.New()
+	cfg.MaintenanceTTL = ttl
+
+	drain, err := durationOr(e.DrainPeriod, defaultDrainPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DrainPeriod: %w", err)
+	}
+
+	cfg.Draining = &atomic.Bool{}
+	cfg.DrainPeriod = drain
+
+	timeouts, err := e.Timeouts.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Timeouts: %w", err)
+	}
+
+	if err := timeouts.validate(drain); err != nil {
+		return nil, fmt.Errorf("failed to parse Timeouts: %w", err)
+	}
+
+	cfg.Timeouts = timeouts
+
+	pool, err := e.Pool.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Pool: %w", err)
+	}
+
+	cfg.Connection.Pool = pool
+
+	cfg.Server = &serverInfo{}
+
+	available, enabled, err := e.parseChecks(cfg.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Checks: %w", err)
+	}
+
+	cfg.AvailableChecks = available
+	cfg.Checks = enabled
+
+	scopes, err := healthcheck.ParseErrorScopes(e.ErrorScopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ErrorScopes: %w", err)
+	}
+
+	cfg.ErrorScopes = scopes
+
+	tables, err := parseStatusTables(e.StatusTables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse StatusTables: %w", err)
+	}
+
+	cfg.StatusTables = tables
+
+	if e.MinVersion != "" {
+		version, err := mariadb.ParseVersion(e.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MinVersion: %w", err)
+		}
+
+		cfg.MinServerVersion = &version
+	}
+
+	readinessOnly, err := boolOr(e.Misconfig, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MisconfigReadinessOnly: %w", err)
+	}
+
+	cfg.Misconfig = &misconfiguration{}
+	cfg.MisconfigReadinessOnly = readinessOnly
+
+	if readinessOnly {
+		slog.Warn("sidecar misconfiguration only fails readiness, liveness is kept green")
+	}
+
+	exporter, err := e.Metrics.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Metrics: %w", err)
+	}
+
+	cfg.Metrics = exporter
+
+	interval, err := durationOr(e.Kube.RoleLabelInterval, defaultRoleLabelInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RoleLabelInterval: %w", err)
+	}
+
+	if interval == 0 {
+		return nil, fmt.Errorf("failed to parse RoleLabelInterval: must be positive")
+	}
+
+	cfg.RoleLabel = e.Kube.RoleLabel
+	cfg.RoleLabelInterval = interval
+
+	events, err := boolOr(e.Kube.Events, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse KubeEvents: %w", err)
+	}
+
+	if cfg.RoleLabel != "" || events {
+		client, err := e.Kube.parse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Kubernetes client: %w", err)
+		}
+
+		cfg.Kube = client
+	}
+
+	if cfg.RoleLabel != "" {
+		slog.Info("pod role label enabled", "label", cfg.RoleLabel, "pod", cfg.Kube.Pod(), "interval", interval)
+	}
+
+	eventsInterval, err := durationOr(e.Kube.EventsMinInterval, defaultKubeEventsMinInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse KubeEventsMinInterval: %w", err)
+	}
+
+	if events {
+		cfg.Events = newPodEvents(cfg.Kube, eventsInterval)
+
+		slog.Info("pod events enabled", "pod", cfg.Kube.Pod(), "min_interval", eventsInterval)
+	}
+
 	return &cfg, nil
 }
+
+// adminToken returns the admin bearer token, preferring the file so the token
+// can be mounted from a Secret. An empty token disables the admin endpoints.
+func (e environment) adminToken() (string, error) {
+	if e.AdminTokenFile == "" {
+		return e.AdminToken, nil
+	}
+
+	data, err := os.ReadFile(e.AdminTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", e.AdminTokenFile, err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("admin token file %s is empty", e.AdminTokenFile)
+	}
+
+	return token, nil
+}
+
+// parseStatusTables parses a list of table[:scope] entries, e.g.
+// "status_memory:liveness,status_aria:readiness". A table without a scope
+// counts against both probes. An empty list yields nil, the default table.
+func parseStatusTables(value string) ([]healthcheck.Table, error) {
+	var tables []healthcheck.Table
+
+	for _, item := range splitList(value) {
+		name, rawScope, hasScope := strings.Cut(item, ":")
+		table := healthcheck.Table{Name: strings.TrimSpace(name), Scope: health.ScopeAll}
+
+		if !mariadb.IsTableName(table.Name) {
+			return nil, fmt.Errorf("invalid table name %q", table.Name)
+		}
+
+		if slices.ContainsFunc(tables, func(t healthcheck.Table) bool { return t.Name == table.Name }) {
+			return nil, fmt.Errorf("duplicate table %q", table.Name)
+		}
+
+		if hasScope {
+			scope, err := healthcheck.ParseScope(strings.TrimSpace(rawScope))
+			if err != nil {
+				return nil, err
+			}
+
+			table.Scope = scope
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// parseChecks builds every optional check. The ones listed in CHECKS run on
+// every probe; the others only when a request asks for them with ?checks=.
+// Checks that depend on the flavor of the server read it from server.
+func (e environment) parseChecks(server *serverInfo) (map[string]health.Checker, []health.Checker, error) {
+	connections, err := e.Connections.parse()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s check: %w", mariadb.CheckConnections, err)
+	}
+
+	semiSync, err := e.SemiSync.parse(server)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s check: %w", mariadb.CheckSemiSync, err)
+	}
+
+	resources, err := e.Resources.parse()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s check: %w", mariadb.CheckResources, err)
+	}
+
+	innodb, err := e.InnoDB.parse()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s check: %w", mariadb.CheckInnoDB, err)
+	}
+
+	transactions, err := e.Transactions.parse()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s check: %w", mariadb.CheckTransactions, err)
+	}
+
+	available := map[string]health.Checker{
+		mariadb.CheckConnections:  connections,
+		mariadb.CheckInnoDB:       innodb,
+		mariadb.CheckResources:    resources,
+		mariadb.CheckSemiSync:     semiSync,
+		mariadb.CheckTransactions: transactions,
+	}
+
+	var enabled []health.Checker
+
+	for _, name := range splitList(e.Checks) {
+		check, ok := available[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown check %q", name)
+		}
+
+		enabled = append(enabled, check)
+
+		slog.Info("enabled check", "name", name)
+	}
+
+	return available, enabled, nil
+}
+
+func (e connectionsEnvironment) parse() (*mariadb.ConnectionCheck, error) {
+	degraded, err := floatOr(e.DegradedPercent, defaultConnectionsDegradedPercent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DegradedPercent: %w", err)
+	}
+
+	unhealthy, err := floatOr(e.UnhealthyPercent, defaultConnectionsUnhealthyPercent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UnhealthyPercent: %w", err)
+	}
+
+	thresholds := mariadb.ConnectionThresholds{
+		DegradedPercent:  degraded,
+		UnhealthyPercent: unhealthy,
+	}
+
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	return mariadb.NewConnectionCheck(thresholds), nil
+}
+
+func (e innodbEnvironment) parse() (*mariadb.InnoDBCheck, error) {
+	var thresholds mariadb.InnoDBThresholds
+
+	for _, field := range []struct {
+		name     string
+		value    string
+		fallback int
+		dst      *uint64
+	}{
+		{"HistoryLength", e.HistoryLength, defaultInnoDBHistoryLength, &thresholds.HistoryLength},
+		{"PendingIO", e.PendingIO, defaultInnoDBPendingIO, &thresholds.PendingIO},
+		{"Deadlocks", e.Deadlocks, defaultInnoDBDeadlocks, &thresholds.Deadlocks},
+	} {
+		value, err := intOr(field.value, field.fallback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field.name, err)
+		}
+
+		if value < 0 {
+			return nil, fmt.Errorf("invalid %s: %d", field.name, value)
+		}
+
+		*field.dst = uint64(value)
+	}
+
+	for _, field := range []struct {
+		name     string
+		value    string
+		fallback float64
+		dst      *float64
+	}{
+		{"CheckpointAgePercent", e.CheckpointAgePercent, defaultInnoDBCheckpointAgePercent, &thresholds.CheckpointAgePercent},
+		{"BufferPoolHitPercent", e.BufferPoolHitPercent, defaultInnoDBBufferPoolHitPercent, &thresholds.BufferPoolHitPercent},
+	} {
+		value, err := floatOr(field.value, field.fallback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field.name, err)
+		}
+
+		*field.dst = value
+	}
+
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	return mariadb.NewInnoDBCheck(thresholds), nil
+}
+
+func (e resourcesEnvironment) parse() (*mariadb.ResourceCheck, error) {
+	var thresholds mariadb.ResourceThresholds
+
+	for _, field := range []struct {
+		name     string
+		value    string
+		fallback float64
+		dst      *float64
+	}{
+		{"OpenFilesPercent", e.OpenFilesPercent, defaultResourcesOpenFilesPercent, &thresholds.OpenFilesPercent},
+		{"OpenedTablesPerSecond", e.OpenedTablesPerSecond, defaultResourcesOpenedTablesPerSecond, &thresholds.OpenedTablesPerSecond},
+		{"ThreadsCreatedPerSecond", e.ThreadsCreatedPerSecond, defaultResourcesThreadsCreatedPerSecond, &thresholds.ThreadsCreatedPerSecond},
+		{"TmpDiskTablesPercent", e.TmpDiskTablesPercent, defaultResourcesTmpDiskTablesPercent, &thresholds.TmpDiskTablesPercent},
+	} {
+		value, err := floatOr(field.value, field.fallback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field.name, err)
+		}
+
+		*field.dst = value
+	}
+
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	return mariadb.NewResourceCheck(thresholds), nil
+}
+
+// parse builds the metrics exporter. It returns nil when metrics are disabled.
+// An empty allowlist falls back to the default one.
+func (e metricsEnvironment) parse() (*metrics.Exporter, error) {
+	enabled, err := boolOr(e.Enabled, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Enabled: %w", err)
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	exporter := &metrics.Exporter{
+		Status:    metrics.DefaultStatus,
+		Variables: metrics.DefaultVariables,
+	}
+
+	if status := splitList(e.Status); len(status) > 0 {
+		exporter.Status = status
+	}
+
+	if variables := splitList(e.Variables); len(variables) > 0 {
+		exporter.Variables = variables
+	}
+
+	if err := exporter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid allowlist: %w", err)
+	}
+
+	slog.Info("metrics enabled", "status", len(exporter.Status), "variables", len(exporter.Variables))
+
+	return exporter, nil
+}
+
+func (e semiSyncEnvironment) parse(server *serverInfo) (*mariadb.SemiSyncCheck, error) {
+	clients, err := intOr(e.MinClients, defaultSemiSyncMinClients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MinClients: %w", err)
+	}
+
+	if clients < 0 {
+		return nil, fmt.Errorf("invalid min clients: %d", clients)
+	}
+
+	check := mariadb.NewSemiSyncCheck(uint64(clients))
+	check.Server = server.get
+
+	return check, nil
+}
+
+func (e transactionsEnvironment) parse() (*mariadb.TransactionCheck, error) {
+	maxAge, err := durationOr(e.MaxAge, defaultTransactionsMaxAge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MaxAge: %w", err)
+	}
+
+	maxLockWait, err := durationOr(e.MaxLockWait, defaultTransactionsMaxLockWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MaxLockWait: %w", err)
+	}
+
+	showQueries, err := boolOr(e.ShowQueries, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ShowQueries: %w", err)
+	}
+
+	thresholds := mariadb.TransactionThresholds{
+		MaxAge:      maxAge,
+		MaxLockWait: maxLockWait,
+	}
+
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	check := mariadb.NewTransactionCheck(thresholds)
+	check.ShowQueries = showQueries
+
+	return check, nil
+}
+
+// parse builds the Kubernetes client for the pod the sidecar runs in.
+func (e kubeEnvironment) parse() (*kube.Client, error) {
+	cfg, err := kube.InClusterConfig(e.PodNamespace, e.PodName, kubeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+
+	cfg.PodUID = e.PodUID
+
+	client, err := kube.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return client, nil
+}
+
+// parse builds the webhook notifier. It returns nil when no URL is configured.
+func (e webhookEnvironment) parse() (*webhook.Notifier, error) {
+	urls := splitList(e.URLs)
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	retries, err := intOr(e.MaxRetries, defaultWebhookMaxRetries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MaxRetries: %w", err)
+	}
+
+	interval, err := durationOr(e.MinInterval, defaultWebhookMinInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MinInterval: %w", err)
+	}
+
+	// The pod name is the most useful identity for whoever gets paged.
+	source, err := os.Hostname()
+	if err != nil {
+		source = "mariadb-healthcheck"
+	}
+
+	notifier, err := webhook.New(webhook.Config{
+		URLs:         urls,
+		Format:       or(e.Format, webhook.FormatCloudEvents),
+		Secret:       e.Secret,
+		Source:       source,
+		MaxRetries:   retries,
+		RetryBackoff: webhookRetryBackoff,
+		MinInterval:  interval,
+		Timeout:      webhookTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notifier: %w", err)
+	}
+
+	slog.Info("webhook notifications enabled", "urls", len(urls), "format", or(e.Format, webhook.FormatCloudEvents))
+
+	return notifier, nil
+}
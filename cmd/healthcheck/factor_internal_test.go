@@ -1,8 +1,15 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/internal/metrics"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -155,4 +162,523 @@ func TestParseEnv(t *testing.T) {
 		require.NoError(t, err)
 		assert.False(t, parsedEnv.DeleteRow)
 	})
+
+	t.Run("should return default history size", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.NotNil(t, parsedEnv.History)
+	})
+
+	t.Run("should disable history when size is zero", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(historySize, "0")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Nil(t, parsedEnv.History)
+	})
+
+	t.Run("should return error for invalid history size", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(historySize, "invalid")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse HistorySize")
+	})
+
+	t.Run("should disable webhooks by default", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Nil(t, parsedEnv.Notifier)
+		assert.NotNil(t, parsedEnv.Health)
+	})
+
+	t.Run("should enable webhooks when URLs are set", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(webhookURLs, "http://a.example, http://b.example")
+		t.Setenv(webhookFormat, "alertmanager")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.NotNil(t, parsedEnv.Notifier)
+	})
+
+	t.Run("should return error for invalid webhook format", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(webhookURLs, "http://a.example")
+		t.Setenv(webhookFormat, "slack")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid webhook format")
+	})
+
+	t.Run("should return error for invalid webhook min interval", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(webhookURLs, "http://a.example")
+		t.Setenv(webhookMinInterval, "soon")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse MinInterval")
+	})
+
+	t.Run("should return error for invalid webhook max retries", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(webhookURLs, "http://a.example")
+		t.Setenv(webhookMaxRetries, "many")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse MaxRetries")
+	})
+
+	t.Run("should disable the role label by default", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Nil(t, parsedEnv.Kube)
+		assert.Equal(t, 10*time.Second, parsedEnv.RoleLabelInterval)
+	})
+
+	t.Run("should return error for a role label outside a cluster", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(roleLabel, "mariadb-role")
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "not running in a cluster")
+	})
+
+	t.Run("should return error for a zero role label interval", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(roleLabelInterval, "0s")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse RoleLabelInterval")
+	})
+
+	t.Run("should default maintenance TTL to one hour", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, parsedEnv.MaintenanceTTL)
+		assert.NotNil(t, parsedEnv.Maintenance)
+		assert.Empty(t, parsedEnv.AdminToken)
+	})
+
+	t.Run("should return error for invalid maintenance TTL", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(maintenanceTTL, "forever")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse MaintenanceTTL")
+	})
+
+	t.Run("should read the admin token from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+		t.Setenv(dbPassword, "test")
+		t.Setenv(adminToken, "from-env")
+		t.Setenv(adminTokenFile, path)
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, "from-file", parsedEnv.AdminToken)
+	})
+
+	t.Run("should return error for a missing admin token file", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(adminTokenFile, filepath.Join(t.TempDir(), "missing"))
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to read admin token")
+	})
+
+	t.Run("should return default drain period", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, parsedEnv.DrainPeriod)
+		require.NotNil(t, parsedEnv.Draining)
+		assert.False(t, parsedEnv.Draining.Load())
+	})
+
+	t.Run("should return error for invalid drain period", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(drainPeriod, "a while")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse DrainPeriod")
+	})
+
+	t.Run("should enable no optional checks by default", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Empty(t, parsedEnv.Checks)
+	})
+
+	t.Run("should enable the connections check", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "connections")
+		t.Setenv(connectionsDegradedPercent, "70")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.Len(t, parsedEnv.Checks, 1)
+		assert.Equal(t, "connections", parsedEnv.Checks[0].Name())
+	})
+
+	t.Run("should enable the semi-sync check", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "connections, semi_sync")
+		t.Setenv(semiSyncMinClients, "2")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.Len(t, parsedEnv.Checks, 2)
+		assert.Equal(t, "semi_sync", parsedEnv.Checks[1].Name())
+		assert.Equal(t, uint64(2), parsedEnv.Checks[1].(*mariadb.SemiSyncCheck).MinClients)
+	})
+
+	t.Run("should return error for negative semi-sync min clients", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(semiSyncMinClients, "-1")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid min clients")
+	})
+
+	t.Run("should enable metrics with the default allowlist", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(metricsEnabled, "true")
+		t.Setenv(metricsVariables, "max_connections")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.NotNil(t, parsedEnv.Metrics)
+		assert.Equal(t, metrics.DefaultStatus, parsedEnv.Metrics.Status)
+		assert.Equal(t, []string{"max_connections"}, parsedEnv.Metrics.Variables)
+	})
+
+	t.Run("should return error for an invalid metrics allowlist", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(metricsEnabled, "true")
+		t.Setenv(metricsStatus, "Threads_connected, Uptime;")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid allowlist")
+	})
+
+	t.Run("should enable the resources check", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "resources")
+		t.Setenv(resourcesTmpDiskTablesPercent, "40")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.Len(t, parsedEnv.Checks, 1)
+		assert.Equal(t, mariadb.ResourceThresholds{
+			OpenFilesPercent:        80,
+			OpenedTablesPerSecond:   10,
+			ThreadsCreatedPerSecond: 10,
+			TmpDiskTablesPercent:    40,
+		}, parsedEnv.Checks[0].(*mariadb.ResourceCheck).Thresholds)
+	})
+
+	t.Run("should return error for invalid resource thresholds", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(resourcesOpenFilesPercent, "150")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid open files percent")
+	})
+
+	t.Run("should enable the innodb check", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "innodb")
+		t.Setenv(innodbHistoryLength, "50000")
+		t.Setenv(innodbBufferPoolHitPercent, "99.5")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.Len(t, parsedEnv.Checks, 1)
+		assert.Equal(t, mariadb.InnoDBThresholds{
+			HistoryLength:        50000,
+			CheckpointAgePercent: 75,
+			PendingIO:            64,
+			BufferPoolHitPercent: 99.5,
+			Deadlocks:            10,
+		}, parsedEnv.Checks[0].(*mariadb.InnoDBCheck).Thresholds)
+	})
+
+	t.Run("should return error for invalid innodb thresholds", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(innodbPendingIO, "0")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid pending I/O")
+	})
+
+	t.Run("should enable the transactions check", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "transactions")
+		t.Setenv(transactionsMaxAge, "5m")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		require.Len(t, parsedEnv.Checks, 1)
+		assert.Equal(t, mariadb.TransactionThresholds{
+			MaxAge:      5 * time.Minute,
+			MaxLockWait: 30 * time.Second,
+		}, parsedEnv.Checks[0].(*mariadb.TransactionCheck).Thresholds)
+	})
+
+	t.Run("should return error for invalid transaction thresholds", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(transactionsMaxLockWait, "500ms")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid max lock wait")
+	})
+
+	t.Run("should parse status tables", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(statusTables, "status_memory:liveness, status_aria:readiness, status_innodb")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, []healthcheck.Table{
+			{Name: "status_memory", Scope: health.ScopeLiveness},
+			{Name: "status_aria", Scope: health.ScopeReadiness},
+			{Name: "status_innodb", Scope: health.ScopeAll},
+		}, parsedEnv.StatusTables)
+	})
+
+	t.Run("should return error for invalid status tables", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+
+		for value, message := range map[string]string{
+			"status-aria":           "invalid table name",
+			"status,status":         "duplicate table",
+			"status_aria:sometimes": "invalid scope",
+		} {
+			t.Setenv(statusTables, value)
+			_, err := getEnv().parseEnv()
+
+			require.Error(t, err)
+			assert.ErrorContains(t, err, message)
+		}
+	})
+
+	t.Run("should parse the minimum server version", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(minServerVersion, "10.11")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, &mariadb.Version{Major: 10, Minor: 11}, parsedEnv.MinServerVersion)
+	})
+
+	t.Run("should return error for an invalid minimum server version", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(minServerVersion, "latest")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse MinVersion")
+	})
+
+	t.Run("should return error when the TLS port is the health port", func(t *testing.T) {
+		dir := t.TempDir()
+		ca := issue(t, dir, "ca", nil)
+
+		t.Setenv(dbPassword, "test")
+		t.Setenv(tlsCertFile, ca.certFile)
+		t.Setenv(tlsKeyFile, ca.keyFile)
+		t.Setenv(tlsPort, "8080")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "TLS_PORT must differ from HEALTH_PORT")
+	})
+
+	t.Run("should return error for an allowlist without an admin listener", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(adminAllow, "127.0.0.1")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "ADMIN_ALLOW requires ADMIN_LISTEN")
+	})
+
+	t.Run("should return error for an allowlist on a socket admin listener", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(adminAllow, "127.0.0.1")
+		t.Setenv(adminListen, "unix:///run/healthcheck-admin.sock")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "ADMIN_ALLOW cannot filter a unix:// ADMIN_LISTEN")
+	})
+
+	t.Run("should return error for an invalid admin listener", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(adminListen, "9090")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse AdminListen")
+	})
+
+	t.Run("should parse a socket listener", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(healthListen, "unix:///run/healthcheck.sock")
+		t.Setenv(healthSocketMode, "0600")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, "unix:///run/healthcheck.sock", parsedEnv.healthAddr())
+		assert.Equal(t, os.FileMode(0o600), parsedEnv.HealthSocketMode)
+	})
+
+	t.Run("should return error when both HEALTH_LISTEN and HEALTH_PORT are set", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(healthListen, "unix:///run/healthcheck.sock")
+		t.Setenv(healthPort, "8080")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "set only one")
+	})
+
+	t.Run("should parse timeouts and the pool", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checkTimeout, "3s")
+		t.Setenv(dbMaxOpenConns, "4")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, parsedEnv.timeouts().Check)
+		assert.Equal(t, 4, parsedEnv.Connection.Pool.MaxOpenConns)
+	})
+
+	t.Run("should return error when draining outlasts the grace period", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(drainPeriod, "30s")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "must be shorter than TERMINATION_GRACE_PERIOD 30s")
+	})
+
+	t.Run("should return error for unknown check", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "bogus")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `unknown check "bogus"`)
+	})
+
+	t.Run("should return error for invalid connection thresholds", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "connections")
+		t.Setenv(connectionsDegradedPercent, "99")
+		t.Setenv(connectionsUnhealthyPercent, "90")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid thresholds")
+	})
+
+	t.Run("should return error for non-numeric connection threshold", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(checks, "connections")
+		t.Setenv(connectionsUnhealthyPercent, "lots")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse UnhealthyPercent")
+	})
+
+	t.Run("should return error for invalid error scopes", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(errorScopes, "lock:never")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse ErrorScopes")
+	})
+
+	t.Run("should disable misconfiguration mode by default", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		parsedEnv, err := getEnv().parseEnv()
+
+		require.NoError(t, err)
+		assert.False(t, parsedEnv.MisconfigReadinessOnly)
+		assert.NotNil(t, parsedEnv.Misconfig)
+	})
+
+	t.Run("should return error for invalid misconfiguration mode", func(t *testing.T) {
+		t.Setenv(dbPassword, "test")
+		t.Setenv(misconfigReadinessOnly, "maybe")
+		_, err := getEnv().parseEnv()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to parse MisconfigReadinessOnly")
+	})
+}
+
+func TestSplitList(t *testing.T) {
+	t.Run("should split and trim items", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b"}, splitList(" a, ,b,"))
+	})
+
+	t.Run("should return nil for empty value", func(t *testing.T) {
+		assert.Nil(t, splitList(""))
+	})
+}
+
+func TestDurationOr(t *testing.T) {
+	t.Run("should return fallback for empty value", func(t *testing.T) {
+		d, err := durationOr("", time.Second)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, d)
+	})
+
+	t.Run("should parse value", func(t *testing.T) {
+		d, err := durationOr("90s", time.Second)
+
+		require.NoError(t, err)
+		assert.Equal(t, 90*time.Second, d)
+	})
+
+	t.Run("should return error for negative value", func(t *testing.T) {
+		_, err := durationOr("-1s", time.Second)
+
+		require.Error(t, err)
+	})
 }
@@ -2,53 +2,65 @@ package main
 
 import (
 	"context"
-	"errors"
 	"log/slog"
+	"maps"
 	"net/http"
+	"slices"
 
-	"github.com/google/uuid"
-	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
 )
 
-func (c config) healthHandler(w http.ResponseWriter, r *http.Request) {
-	id := uuid.New()
+// healthHandler serves /health through HealthCheck, tracked by the watchdog.
+func (c config) healthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer c.Watchdog.Track()()
 
-	slog.Debug(
-		"generated UUID",
-		"value", id,
-	)
+		c.HealthCheck.ServeHTTP(w, r)
+	})
+}
+
+// checkOptions configures the embeddable healthcheck like the sidecar:
+// draining and maintenance answer before the database is touched, and every
+// outcome feeds the history, the state tracker and the misconfiguration
+// report.
+func (c config) checkOptions() healthcheck.Options {
+	options := healthcheck.Options{
+		DB:           c.DBInterface,
+		Tables:       c.StatusTables,
+		KeepRows:     !c.DeleteRow,
+		Checks:       c.Checks,
+		Available:    slices.Collect(maps.Values(c.AvailableChecks)),
+		ErrorScope:   c.errorScope,
+		Timeout:      c.timeouts().Check,
+		StageTimeout: c.timeouts().Stage,
+		Intercept: func(w http.ResponseWriter, probe healthcheck.Probe) bool {
+			return c.serveDraining(w, probe) || c.serveMaintenance(w, probe)
+		},
+		Observe: c.record,
+	}
+
+	if c.MinServerVersion != nil {
+		options.Gates = append(options.Gates, healthcheck.CheckerFunc(checkVersion,
+			func(ctx context.Context, _ health.DB) health.Result { return c.versionGate(ctx) }))
+	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), contextTimeout)
-	defer cancel()
+	return options
+}
 
-	err := mariadb.RunCheck(ctx, c.DBInterface, id.String(), c.DeleteRow)
+// record handles the outcome of a probe. A failed round-trip also drops the
+// remembered server, as it may be restarting with a new version.
+func (c config) record(outcome healthcheck.Outcome) {
+	err := outcome.Err()
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Misconfig.note(err, c.MisconfigReadinessOnly)
 
-	if err == nil {
-		w.WriteHeader(http.StatusOK)
-		writeBody(w, "OK")
-		return
+	if err != nil {
+		c.Server.forget()
 	}
 
-	slog.ErrorContext(ctx, "healthcheck failed", "error", err)
-
-	w.WriteHeader(http.StatusInternalServerError)
-
-	switch {
-	case errors.Is(err, mariadb.ErrInsert):
-		writeBody(w, "failed to insert row")
-	case errors.Is(err, mariadb.ErrSelect):
-		writeBody(w, "failed to select row")
-	case errors.Is(err, mariadb.ErrScan):
-		writeBody(w, "failed to scan row")
-	case errors.Is(err, mariadb.ErrValidate):
-		writeBody(w, "failed to validate row")
-	case errors.Is(err, mariadb.ErrDelete):
-		writeBody(w, "failed to delete row")
-	default:
-		writeBody(w, "healthcheck failed")
-	}
+	c.recordHistory(outcome)
+	c.observe(outcome)
 }
 
 func writeBody(w http.ResponseWriter, message string) {
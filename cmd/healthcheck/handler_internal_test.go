@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +23,25 @@ func decodeHTTPBody(t *testing.T, resp *http.Response) string {
 	return string(body)
 }
 
+// withHealthCheck builds the HealthCheck of c like run does.
+func withHealthCheck(t *testing.T, c config) config {
+	t.Helper()
+
+	handler, err := healthcheck.New(c.checkOptions())
+	require.NoError(t, err)
+
+	c.HealthCheck = handler
+
+	return c
+}
+
+// healthHandler returns the /health handler of c.
+func healthHandler(t *testing.T, c config) http.Handler {
+	t.Helper()
+
+	return withHealthCheck(t, c).healthHandler()
+}
+
 func TestHealthHandler(t *testing.T) {
 	t.Run("should return failed to insert row", func(t *testing.T) {
 		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
@@ -36,11 +56,9 @@ func TestHealthHandler(t *testing.T) {
 		defer db.Close()
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+			}),
 		)
 		defer server.Close()
 
@@ -69,11 +87,9 @@ func TestHealthHandler(t *testing.T) {
 			WillReturnError(errors.New("select failed"))
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+			}),
 		)
 		defer server.Close()
 
@@ -108,11 +124,9 @@ func TestHealthHandler(t *testing.T) {
 			WillReturnRows(rows)
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+			}),
 		)
 		defer server.Close()
 
@@ -141,11 +155,9 @@ func TestHealthHandler(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"uuid"}))
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+			}),
 		)
 		defer server.Close()
 
@@ -174,12 +186,10 @@ func TestHealthHandler(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("any-uuid"))
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-					DeleteRow:   false,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+				DeleteRow:   false,
+			}),
 		)
 		defer server.Close()
 
@@ -212,12 +222,10 @@ func TestHealthHandler(t *testing.T) {
 			WillReturnError(errors.New("delete failed"))
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-					DeleteRow:   true,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+				DeleteRow:   true,
+			}),
 		)
 		defer server.Close()
 
@@ -250,12 +258,10 @@ func TestHealthHandler(t *testing.T) {
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		server := httptest.NewServer(
-			http.HandlerFunc(
-				config{
-					DBInterface: db,
-					DeleteRow:   true,
-				}.healthHandler,
-			),
+			healthHandler(t, config{
+				DBInterface: db,
+				DeleteRow:   true,
+			}),
 		)
 		defer server.Close()
 
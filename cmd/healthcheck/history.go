@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+)
+
+// recordHistory appends the outcome of a single probe to the history ring.
+// The round-trip stages and every optional check are recorded as stages.
+func (c config) recordHistory(outcome healthcheck.Outcome) {
+	p, stages, report := outcome.Probe, outcome.Stages(), outcome.Report
+
+	entry := history.Entry{
+		Time:     outcome.Start,
+		Probe:    string(p),
+		Outcome:  history.OutcomeOK,
+		Duration: time.Since(outcome.Start),
+		Stages:   make([]history.Stage, 0, len(stages)+len(report.Results)),
+	}
+
+	for _, stage := range stages {
+		entry.Stages = append(entry.Stages, history.Stage{
+			Name:     stage.Name,
+			Duration: stage.Duration,
+		})
+	}
+
+	for _, result := range report.Results {
+		if healthcheck.IsRoundTrip(result.Name) {
+			continue
+		}
+
+		entry.Stages = append(entry.Stages, history.Stage{
+			Name:     result.Name,
+			Duration: result.Duration,
+		})
+	}
+
+	switch report.Status(p.Scope()) {
+	case health.StatusUnhealthy:
+		entry.Outcome = history.OutcomeFailed
+	case health.StatusDegraded:
+		entry.Outcome = history.OutcomeDegraded
+	}
+
+	if worst, ok := report.Worst(p.Scope()); ok {
+		entry.Error = worst.Message
+	}
+
+	c.History.Add(entry)
+}
+
+// historyHandler serves the recorded checks, oldest first. JSON is returned by
+// default; CSV is returned for ?format=csv or an Accept header of text/csv.
+func (c config) historyHandler(w http.ResponseWriter, r *http.Request) {
+	entries := c.History.Entries()
+
+	if r.URL.Query().Get("format") == "csv" ||
+		strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+		if err := history.WriteCSV(w, entries); err != nil {
+			slog.Error("failed to write history", "error", err)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		slog.Error("failed to write history", "error", err)
+	}
+}
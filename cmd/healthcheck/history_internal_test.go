@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	newConfig := func(t *testing.T) config {
+		t.Helper()
+
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(errors.New("insert failed"))
+
+		return config{
+			DBInterface: db,
+			History:     history.NewRing(10),
+		}
+	}
+
+	t.Run("should record a failed probe and serve it as JSON", func(t *testing.T) {
+		cfg := newConfig(t)
+
+		healthHandler(t, cfg).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health?probe=liveness", nil))
+
+		w := httptest.NewRecorder()
+		cfg.historyHandler(w, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+		var entries []history.Entry
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		require.Len(t, entries, 1)
+		assert.Equal(t, "liveness", entries[0].Probe)
+		assert.Equal(t, history.OutcomeFailed, entries[0].Outcome)
+		assert.Contains(t, entries[0].Error, "failed to insert row")
+		require.Len(t, entries[0].Stages, 1)
+		assert.Equal(t, "insert", entries[0].Stages[0].Name)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("should serve CSV when requested", func(t *testing.T) {
+		cfg := newConfig(t)
+
+		healthHandler(t, cfg).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		w := httptest.NewRecorder()
+		cfg.historyHandler(w, httptest.NewRequest(http.MethodGet, "/history?format=csv", nil))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], ",unknown,failed,")
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("should return an empty list when history is disabled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		config{}.historyHandler(w, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+		assert.JSONEq(t, "[]", w.Body.String())
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// watchRole keeps the RoleLabel label of the pod in sync with the role of the
+// server, so a plain Service can select the current primary. The role is
+// detected every interval; the pod is only patched when the role changed or
+// the previous patch failed. It does nothing when no label is configured.
+func (c config) watchRole(ctx context.Context, interval time.Duration) {
+	if c.RoleLabel == "" || c.Kube == nil {
+		return
+	}
+
+	const name = "role-labeler"
+
+	c.Watchdog.Register(name, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var labeled mariadb.Role
+
+	for {
+		labeled = c.labelRole(ctx, labeled)
+		c.Watchdog.Beat(name)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// labelRole patches the pod when the role differs from labeled, the role the
+// pod is currently labeled with. It returns the role the pod is labeled with
+// afterwards.
+func (c config) labelRole(ctx context.Context, labeled mariadb.Role) mariadb.Role {
+	detectCtx, cancel := context.WithTimeout(ctx, c.timeouts().Check)
+	defer cancel()
+
+	topology, err := c.detectTopology(detectCtx)
+	if err != nil {
+		slog.Warn("failed to detect role, keeping pod label", "label", c.RoleLabel, "error", err)
+		return labeled
+	}
+
+	if topology.Role == labeled {
+		return labeled
+	}
+
+	if err := c.Kube.PatchPodLabels(ctx, map[string]string{c.RoleLabel: string(topology.Role)}); err != nil {
+		slog.Error("failed to update pod label", "label", c.RoleLabel, "role", topology.Role, "error", err)
+		return labeled
+	}
+
+	slog.Info("updated pod label", "label", c.RoleLabel, "from", labeled, "to", topology.Role)
+
+	return topology.Role
+}
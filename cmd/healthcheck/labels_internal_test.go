@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeKube starts a local stand-in for the Kubernetes API server answering
+// requests with statuses in turn, repeating the last one, and returns a client
+// for it together with the bodies it received.
+func newFakeKube(t *testing.T, statuses ...int) (*kube.Client, func() []map[string]any) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		status := statuses[min(len(bodies), len(statuses)-1)]
+		bodies = append(bodies, body)
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := kube.New(kube.Config{Host: server.URL, Namespace: "db", Pod: "mariadb-0", PodUID: "pod-uid"})
+	require.NoError(t, err)
+
+	return client, func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return bodies
+	}
+}
+
+func TestLabelRole(t *testing.T) {
+	t.Run("should patch the pod when the role changed", func(t *testing.T) {
+		client, bodies := newFakeKube(t, http.StatusOK)
+		cfg := config{DBInterface: newRoleDB(t, "OFF", 0), Kube: client, RoleLabel: "mariadb-role"}
+
+		labeled := cfg.labelRole(t.Context(), mariadb.RoleReplica)
+
+		assert.Equal(t, mariadb.RolePrimary, labeled)
+		assert.Equal(t, []map[string]any{
+			{"metadata": map[string]any{"labels": map[string]any{"mariadb-role": "primary"}}},
+		}, bodies())
+	})
+
+	t.Run("should not patch the pod when the role is unchanged", func(t *testing.T) {
+		client, bodies := newFakeKube(t, http.StatusOK)
+		cfg := config{DBInterface: newRoleDB(t, "ON", 1), Kube: client, RoleLabel: "mariadb-role"}
+
+		labeled := cfg.labelRole(t.Context(), mariadb.RoleReplica)
+
+		assert.Equal(t, mariadb.RoleReplica, labeled)
+		assert.Empty(t, bodies())
+	})
+
+	t.Run("should retry when the patch failed", func(t *testing.T) {
+		client, bodies := newFakeKube(t, http.StatusForbidden)
+		cfg := config{DBInterface: newRoleDB(t, "OFF", 0), Kube: client, RoleLabel: "mariadb-role"}
+
+		labeled := cfg.labelRole(t.Context(), "")
+
+		assert.Empty(t, labeled)
+		assert.Len(t, bodies(), 1)
+	})
+
+	t.Run("should keep the label when the role cannot be detected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL VARIABLES").WillReturnError(assert.AnError)
+
+		client, bodies := newFakeKube(t, http.StatusOK)
+		cfg := config{DBInterface: db, Kube: client, RoleLabel: "mariadb-role"}
+
+		labeled := cfg.labelRole(t.Context(), mariadb.RolePrimary)
+
+		assert.Equal(t, mariadb.RolePrimary, labeled)
+		assert.Empty(t, bodies())
+	})
+}
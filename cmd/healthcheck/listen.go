@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unixScheme prefixes a HEALTH_LISTEN or ADMIN_LISTEN address naming a Unix
+// domain socket.
+const unixScheme = "unix://"
+
+// healthAddr returns the address of the probe listener: HEALTH_LISTEN when
+// set, HEALTH_PORT on every interface otherwise.
+func (c config) healthAddr() string {
+	if c.HealthListen != "" {
+		return c.HealthListen
+	}
+
+	return fmt.Sprintf(":%d", c.HealthPort)
+}
+
+// parseListen validates a HEALTH_LISTEN or ADMIN_LISTEN address: unix://
+// followed by an absolute socket path, or a TCP host:port.
+func parseListen(value string) (string, error) {
+	if path, ok := strings.CutPrefix(value, unixScheme); ok {
+		if !filepath.IsAbs(path) {
+			return "", fmt.Errorf("invalid socket path %q, expected an absolute path", path)
+		}
+
+		return value, nil
+	}
+
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		return "", fmt.Errorf("invalid address %q, expected unix:///path or host:port: %w", value, err)
+	}
+
+	return value, nil
+}
+
+// parseFileMode parses an octal permission such as 0660.
+func parseFileMode(value string, fallback os.FileMode) (os.FileMode, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > uint64(fs.ModePerm) {
+		return 0, fmt.Errorf("invalid file mode %q, expected octal permissions such as 0660", value)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// listen opens addr. A Unix domain socket left behind by a previous run is
+// removed first and the new one gets mode, so co-located agents in the same
+// group can connect; it is removed again when the listener is closed.
+func listen(addr string, mode os.FileMode) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixScheme)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+
+		return nil, fmt.Errorf("failed to set mode of %s: %w", path, err)
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes the socket at path, refusing to remove anything
+// that is not a socket.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	return os.Remove(path)
+}
+
+// serve runs server until it is shut down, over TLS when it has a TLS
+// configuration.
+func (c config) serve(server *http.Server) error {
+	listener, err := listen(server.Addr, c.HealthSocketMode)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", server.Addr, err)
+	}
+
+	if server.TLSConfig != nil {
+		slog.Info("starting health check server", "address", server.Addr, "tls", true,
+			"client_auth", server.TLSConfig.ClientAuth == tls.RequireAndVerifyClientCert)
+
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		slog.Info("starting health check server", "address", server.Addr, "tls", false)
+
+		err = server.Serve(listener)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve on %s: %w", server.Addr, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/watchdog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketPath returns a socket path short enough for the sun_path limit.
+func socketPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "hc")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "healthcheck.sock")
+}
+
+func TestParseListen(t *testing.T) {
+	t.Run("should accept a socket and a TCP address", func(t *testing.T) {
+		for _, value := range []string{"unix:///run/healthcheck.sock", "127.0.0.1:8080", ":8080"} {
+			addr, err := parseListen(value)
+
+			require.NoError(t, err)
+			assert.Equal(t, value, addr)
+		}
+	})
+
+	t.Run("should return error for invalid addresses", func(t *testing.T) {
+		for value, message := range map[string]string{
+			"unix://run/healthcheck.sock": "expected an absolute path",
+			"8080":                        "expected unix:///path or host:port",
+		} {
+			_, err := parseListen(value)
+
+			assert.ErrorContains(t, err, message)
+		}
+	})
+}
+
+func TestParseFileMode(t *testing.T) {
+	t.Run("should parse octal permissions", func(t *testing.T) {
+		mode, err := parseFileMode("0600", defaultSocketMode)
+
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), mode)
+	})
+
+	t.Run("should return the fallback when unset", func(t *testing.T) {
+		mode, err := parseFileMode("", defaultSocketMode)
+
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o660), mode)
+	})
+
+	t.Run("should return error for invalid permissions", func(t *testing.T) {
+		for _, value := range []string{"rw-rw----", "0999", "01777"} {
+			_, err := parseFileMode(value, defaultSocketMode)
+
+			assert.ErrorContains(t, err, "invalid file mode")
+		}
+	})
+}
+
+func TestUnixSocket(t *testing.T) {
+	t.Run("should serve probes on the socket with the configured mode", func(t *testing.T) {
+		path := socketPath(t)
+		cfg := config{HealthListen: unixScheme + path, HealthSocketMode: 0o600, Watchdog: watchdog.New()}
+		server := setupServer(cfg)
+
+		done := make(chan error, 1)
+		go func() { done <- cfg.serve(server) }()
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://healthcheck/self", nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "OK", string(body))
+
+		require.NoError(t, server.Close())
+		require.NoError(t, <-done)
+
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should replace a stale socket", func(t *testing.T) {
+		path := socketPath(t)
+
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		stale.Close()
+
+		listener, err := listen(unixScheme+path, defaultSocketMode)
+
+		require.NoError(t, err)
+		listener.Close()
+	})
+
+	t.Run("should not remove a regular file", func(t *testing.T) {
+		path := socketPath(t)
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+		_, err := listen(unixScheme+path, defaultSocketMode)
+
+		assert.ErrorContains(t, err, "is not a socket")
+	})
+}
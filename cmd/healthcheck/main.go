@@ -1,10 +1,10 @@
 // Package main is the entry point for the healthcheck command.
-// It parses the environment variables and starts the HTTP server.
+// It parses the environment variables and starts the HTTP server, or
+// diagnoses the installation when run as "healthcheck doctor".
 package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,6 +13,8 @@ import (
 	"syscall"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
 )
 
 var (
@@ -25,6 +27,20 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == commandDoctor {
+		ok, err := runDoctor(context.Background(), os.Stdout)
+		if err != nil {
+			slog.Error("doctor failed", "error", err)
+			os.Exit(1)
+		}
+
+		if !ok {
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	if err := run(); err != nil {
 		slog.Error("application error", "error", err)
 		os.Exit(1)
@@ -46,17 +62,67 @@ func (c config) getLogLevel() (slog.Level, error) {
 	}
 }
 
+// setupServer returns the server of HEALTH_PORT. It carries the admin
+// endpoints as well unless they have a listener of their own.
 func setupServer(config config) *http.Server {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health", config.healthHandler)
+	mux.Handle("/health", config.healthHandler())
+	mux.HandleFunc("/self", config.selfHandler)
+	mux.HandleFunc("/role", config.roleHandler)
+	mux.HandleFunc("/primary", config.requireRole(mariadb.RolePrimary))
+	mux.HandleFunc("/replica", config.requireRole(mariadb.RoleReplica))
+
+	if config.AdminListen == "" {
+		config.adminRoutes(mux)
+	}
+
+	return config.newServer(config.healthAddr(), mux)
+}
+
+// setupAdminServer returns the server of ADMIN_LISTEN, or nil when the admin
+// endpoints share HEALTH_PORT. Every request is checked against ADMIN_ALLOW
+// and, when credentials are configured, has to authenticate.
+func setupAdminServer(config config) *http.Server {
+	if config.AdminListen == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	config.adminRoutes(mux)
+
+	var handler http.Handler = mux
+	if config.hasCredentials() {
+		handler = config.requireCredentials(handler)
+	}
+
+	return config.newServer(config.AdminListen, config.requireAllowed(handler))
+}
+
+// adminRoutes registers the endpoints that are not needed by probes. The
+// maintenance switch is only registered when credentials are configured.
+func (c config) adminRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/history", c.historyHandler)
+	mux.HandleFunc("/version", c.versionHandler)
+
+	if c.Metrics != nil {
+		mux.HandleFunc("/metrics", c.metricsHandler)
+	}
+
+	if c.hasCredentials() {
+		mux.Handle("/admin/maintenance", c.requireCredentials(http.HandlerFunc(c.maintenanceHandler)))
+	}
+}
+
+func (c config) newServer(addr string, handler http.Handler) *http.Server {
+	timeouts := c.timeouts()
 
 	return &http.Server{
-		Addr:              fmt.Sprintf(":%d", config.HealthPort),
-		Handler:           mux,
-		ReadTimeout:       httpReadTimeout,
-		ReadHeaderTimeout: httpReadHeaderTimeout,
-		WriteTimeout:      httpWriteTimeout,
-		IdleTimeout:       httpIdleTimeout,
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       timeouts.HTTPRead,
+		ReadHeaderTimeout: timeouts.HTTPReadHeader,
+		WriteTimeout:      timeouts.HTTPWrite,
+		IdleTimeout:       timeouts.HTTPIdle,
 	}
 }
 
@@ -83,35 +149,36 @@ func run() error {
 
 	config.DBInterface = db
 
-	server := setupServer(*config)
+	config.HealthCheck, err = healthcheck.New(config.checkOptions())
+	if err != nil {
+		return fmt.Errorf("failed to set up healthcheck: %w", err)
+	}
+
+	servers := config.servers()
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	go awaitShutdown(ctx, server)
+	go config.Notifier.Run(ctx)
+	go config.Events.Run(ctx)
+	go watchMaintenanceSignal(ctx, config.Maintenance, config.MaintenanceTTL)
+	go config.watchMisconfiguration(ctx, misconfigLogInterval)
+	go config.selfTest(ctx)
+	go config.watchRole(ctx, config.RoleLabelInterval)
+	go config.watchCertificate(ctx, certReloadInterval)
 
-	slog.Info(
-		"starting health check server",
-		"port", config.HealthPort,
-	)
+	errs := make(chan error, len(servers))
 
-	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		return fmt.Errorf("failed to start server: %w", err)
+	for _, server := range servers {
+		go awaitShutdown(ctx, server, config.Draining, config.DrainPeriod, config.timeouts().Shutdown)
+		go func() { errs <- config.serve(server) }()
 	}
 
-	return nil
-}
-
-// awaitShutdown blocks until ctx is canceled, then triggers a graceful
-// shutdown of server bounded by shutdownTimeout. Extracted from run() so
-// it can be exercised in unit tests.
-func awaitShutdown(ctx context.Context, server *http.Server) {
-	<-ctx.Done()
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
-
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		slog.Error("graceful shutdown failed", "error", err)
+	for range servers {
+		if err := <-errs; err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
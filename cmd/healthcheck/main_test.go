@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"net/http"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,16 +19,25 @@ func TestSetupServer(t *testing.T) {
 
 		assert.Equal(t, ":8080", server.Addr)
 		assert.NotNil(t, server.Handler)
-		assert.Equal(t, httpReadTimeout, server.ReadTimeout)
-		assert.Equal(t, httpWriteTimeout, server.WriteTimeout)
-		assert.Equal(t, httpReadHeaderTimeout, server.ReadHeaderTimeout)
-		assert.Equal(t, httpIdleTimeout, server.IdleTimeout)
+		assert.Equal(t, defaultHTTPReadTimeout, server.ReadTimeout)
+		assert.Equal(t, defaultHTTPWriteTimeout, server.WriteTimeout)
+		assert.Equal(t, defaultHTTPReadHeaderTimeout, server.ReadHeaderTimeout)
+		assert.Equal(t, defaultHTTPIdleTimeout, server.IdleTimeout)
 
 		// Test server closes properly
 		err := server.Close()
 		assert.NoError(t, err, "Server should close without error")
 	})
 
+	t.Run("should apply the configured timeouts", func(t *testing.T) {
+		timeouts := defaultTimeouts()
+		timeouts.HTTPWrite = 20 * time.Second
+
+		server := setupServer(config{HealthPort: 8080, Timeouts: timeouts})
+
+		assert.Equal(t, 20*time.Second, server.WriteTimeout)
+	})
+
 	t.Run("should set up ServeMux", func(t *testing.T) {
 		server := setupServer(config{
 			HealthPort: 8080,
@@ -85,7 +95,7 @@ func TestAwaitShutdown(t *testing.T) {
 
 		done := make(chan struct{})
 		go func() {
-			awaitShutdown(ctx, srv)
+			awaitShutdown(ctx, srv, &atomic.Bool{}, 0, defaultShutdownTimeout)
 			close(done)
 		}()
 
@@ -109,7 +119,7 @@ func TestAwaitShutdown(t *testing.T) {
 
 		done := make(chan struct{})
 		go func() {
-			awaitShutdown(ctx, srv)
+			awaitShutdown(ctx, srv, &atomic.Bool{}, 0, defaultShutdownTimeout)
 			close(done)
 		}()
 
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/internal/maintenance"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+)
+
+// serveMaintenance answers a probe without touching the database while
+// maintenance is on. Readiness fails so traffic drains; every other probe
+// succeeds so MariaDB is not restarted during planned work. It returns false
+// when maintenance is off and the probe must be handled normally.
+func (c config) serveMaintenance(w http.ResponseWriter, p healthcheck.Probe) bool {
+	state := c.Maintenance.Current()
+	if !state.Active {
+		return false
+	}
+
+	c.History.Add(history.Entry{
+		Time:    time.Now(),
+		Probe:   string(p),
+		Outcome: history.OutcomeMaintenance,
+	})
+
+	if p == healthcheck.ProbeReadiness {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	writeBody(w, "maintenance")
+
+	return true
+}
+
+// maintenanceHandler reports (GET), enables (POST) or disables (DELETE)
+// maintenance. POST accepts optional "ttl" and "reason" query parameters;
+// without ttl the configured MAINTENANCE_TTL applies and ttl=0 disables expiry.
+func (c config) maintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	var state maintenance.State
+
+	switch r.Method {
+	case http.MethodGet:
+		state = c.Maintenance.Current()
+	case http.MethodPost:
+		ttl, err := durationOr(r.URL.Query().Get("ttl"), c.MaintenanceTTL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		state = c.Maintenance.Enable(ttl, or(r.URL.Query().Get("reason"), "admin API"))
+		slog.Warn("maintenance enabled", "until", state.Until, "reason", state.Reason)
+	case http.MethodDelete:
+		state = c.Maintenance.Disable()
+		slog.Warn("maintenance disabled")
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		slog.Error("failed to write maintenance state", "error", err)
+	}
+}
+
+// watchMaintenanceSignal toggles maintenance on every SIGUSR1 until ctx is
+// canceled.
+func watchMaintenanceSignal(ctx context.Context, mode *maintenance.Mode, ttl time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			state := mode.Toggle(ttl, "SIGUSR1")
+			slog.Warn(
+				"maintenance toggled by signal",
+				"active", state.Active,
+				"until", state.Until,
+			)
+		}
+	}
+}
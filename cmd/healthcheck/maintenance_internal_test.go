@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/internal/maintenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeMaintenance(t *testing.T) {
+	// No DBInterface: any attempt to reach the database would panic.
+	newConfig := func() config {
+		cfg := config{
+			History:     history.NewRing(10),
+			Maintenance: maintenance.New(),
+		}
+		cfg.Maintenance.Enable(0, "test")
+
+		return cfg
+	}
+
+	t.Run("should fail readiness without touching the database", func(t *testing.T) {
+		cfg := newConfig()
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "maintenance", w.Body.String())
+		require.Len(t, cfg.History.Entries(), 1)
+		assert.Equal(t, history.OutcomeMaintenance, cfg.History.Entries()[0].Outcome)
+	})
+
+	t.Run("should keep liveness green", func(t *testing.T) {
+		cfg := newConfig()
+
+		for _, target := range []string{"/health?probe=liveness", "/health?probe=startup", "/health"} {
+			w := httptest.NewRecorder()
+
+			healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code, target)
+			assert.Equal(t, "maintenance", w.Body.String(), target)
+		}
+	})
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	newServer := func(t *testing.T) (*httptest.Server, config) {
+		t.Helper()
+
+		cfg := config{
+			AdminToken:     "t0ken",
+			Maintenance:    maintenance.New(),
+			MaintenanceTTL: time.Hour,
+		}
+
+		server := httptest.NewServer(setupServer(cfg).Handler)
+		t.Cleanup(server.Close)
+
+		return server, cfg
+	}
+
+	do := func(t *testing.T, method, url, token string) (*http.Response, maintenance.State) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), method, url, nil)
+		require.NoError(t, err)
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var state maintenance.State
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+		}
+
+		return resp, state
+	}
+
+	t.Run("should reject requests without a valid token", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		resp, _ := do(t, http.MethodPost, server.URL+"/admin/maintenance", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, _ = do(t, http.MethodPost, server.URL+"/admin/maintenance", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should enable with the default ttl and disable", func(t *testing.T) {
+		server, cfg := newServer(t)
+
+		resp, state := do(t, http.MethodPost, server.URL+"/admin/maintenance?reason=upgrade", "t0ken")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, state.Active)
+		assert.Equal(t, "upgrade", state.Reason)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), state.Until, time.Minute)
+		assert.True(t, cfg.Maintenance.Current().Active)
+
+		_, state = do(t, http.MethodGet, server.URL+"/admin/maintenance", "t0ken")
+		assert.True(t, state.Active)
+
+		_, state = do(t, http.MethodDelete, server.URL+"/admin/maintenance", "t0ken")
+		assert.False(t, state.Active)
+		assert.False(t, cfg.Maintenance.Current().Active)
+	})
+
+	t.Run("should accept an explicit ttl", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		_, state := do(t, http.MethodPost, server.URL+"/admin/maintenance?ttl=0", "t0ken")
+
+		assert.True(t, state.Active)
+		assert.True(t, state.Until.IsZero())
+	})
+
+	t.Run("should return error for an invalid ttl", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		resp, _ := do(t, http.MethodPost, server.URL+"/admin/maintenance?ttl=later", "t0ken")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should reject unsupported methods", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		resp, _ := do(t, http.MethodPut, server.URL+"/admin/maintenance", "t0ken")
+
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("should not register admin endpoints without a token", func(t *testing.T) {
+		server := httptest.NewServer(setupServer(config{}).Handler)
+		defer server.Close()
+
+		resp, _ := do(t, http.MethodGet, server.URL+"/admin/maintenance", "")
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestWatchMaintenanceSignal(t *testing.T) {
+	t.Run("should toggle maintenance on SIGUSR1", func(t *testing.T) {
+		mode := maintenance.New()
+
+		done := make(chan struct{})
+		go func() {
+			watchMaintenanceSignal(t.Context(), mode, time.Minute)
+			close(done)
+		}()
+
+		// Let the watcher register for the signal.
+		time.Sleep(50 * time.Millisecond)
+
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+		assert.Eventually(t, func() bool { return mode.Current().Active }, time.Second, 10*time.Millisecond)
+		assert.False(t, mode.Current().Until.IsZero())
+	})
+}
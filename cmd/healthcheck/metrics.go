@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/metrics"
+)
+
+// metricsHandler re-exports the allowlisted server status and variables in
+// the Prometheus text format. A server that cannot be queried is reported as
+// mysql_up 0 with status 200, so the scrape itself does not fail.
+func (c config) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	defer c.Watchdog.Track()()
+
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeouts().Check)
+	defer cancel()
+
+	samples, err := c.Metrics.Collect(ctx, c.DBInterface)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to collect metrics", "error", err)
+	}
+
+	samples = append(samples, metrics.Up(err == nil))
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+
+	if err := metrics.Write(w, samples); err != nil {
+		slog.Error("failed to write metrics", "error", err)
+	}
+}
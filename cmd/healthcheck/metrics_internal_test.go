@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	exporter := &metrics.Exporter{Status: []string{"Threads_connected"}}
+
+	t.Run("should export the allowlisted status", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL STATUS WHERE Variable_name IN ('Threads_connected')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Threads_connected", "7"))
+
+		w := httptest.NewRecorder()
+		config{DBInterface: db, Metrics: exporter}.metricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "\nmysql_global_status_threads_connected 7\n")
+		assert.Contains(t, w.Body.String(), "\nmysql_up 1\n")
+	})
+
+	t.Run("should report mysql_up 0 when the server cannot be queried", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL STATUS").WillReturnError(assert.AnError)
+
+		w := httptest.NewRecorder()
+		config{DBInterface: db, Metrics: exporter}.metricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "threads_connected")
+		assert.Contains(t, w.Body.String(), "\nmysql_up 0\n")
+	})
+
+	t.Run("should not register /metrics by default", func(t *testing.T) {
+		server := httptest.NewServer(setupServer(config{}).Handler)
+		defer server.Close()
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/metrics", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// misconfiguration remembers whether the last round-trip failed because of
+// the sidecar's own configuration. All methods on a nil *misconfiguration are
+// no-ops.
+type misconfiguration struct {
+	mu       sync.Mutex
+	category mariadb.ErrorCategory
+	err      error
+	since    time.Time
+}
+
+// note records the outcome of a round-trip and logs loudly when the sidecar
+// becomes misconfigured. Any other failure keeps what was remembered, as it
+// does not tell whether the configuration was fixed; only a successful
+// round-trip resolves it.
+func (m *misconfiguration) note(err error, readinessOnly bool) {
+	if m == nil {
+		return
+	}
+
+	category := mariadb.Classify(err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil {
+		if m.err != nil {
+			slog.Info("sidecar configuration problem resolved", "category", m.category)
+		}
+
+		m.category, m.err = "", nil
+
+		return
+	}
+
+	if !category.IsMisconfiguration() {
+		return
+	}
+
+	if m.err == nil {
+		m.since = time.Now()
+		logMisconfiguration(category, err, m.since, readinessOnly)
+	}
+
+	m.category, m.err = category, err
+}
+
+// report logs the current misconfiguration, if any.
+func (m *misconfiguration) report(readinessOnly bool) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		logMisconfiguration(m.category, m.err, m.since, readinessOnly)
+	}
+}
+
+func logMisconfiguration(category mariadb.ErrorCategory, err error, since time.Time, readinessOnly bool) {
+	consequence := "liveness fails and MariaDB gets restarted although the fault is in the sidecar; " +
+		"set MISCONFIG_READINESS_ONLY=true to only fail readiness"
+	if readinessOnly {
+		consequence = "only readiness fails, liveness is kept green so MariaDB is not restarted"
+	}
+
+	slog.Error(
+		"SIDECAR MISCONFIGURED: check DB_USER, DB_PASSWORD, DB_NAME, the GRANTs and the status table",
+		"category", category,
+		"since", since,
+		"consequence", consequence,
+		"error", err,
+	)
+}
+
+// watchMisconfiguration repeats the misconfiguration log line every interval
+// until ctx is canceled, so the problem does not scroll out of sight.
+func (c config) watchMisconfiguration(ctx context.Context, interval time.Duration) {
+	const name = "misconfiguration-watcher"
+
+	c.Watchdog.Register(name, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Misconfig.report(c.MisconfigReadinessOnly)
+			c.Watchdog.Beat(name)
+		}
+	}
+}
+
+// selfTest runs one round-trip at startup so a wrong password or a missing
+// GRANT is reported immediately instead of on the first failing probe. Other
+// failures are ignored: MariaDB is often still starting at this point. The
+// row is deleted or kept like on probes. On success the server version is
+// detected and logged.
+func (c config) selfTest(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts().Check)
+	defer cancel()
+
+	var err error
+
+	for _, table := range c.statusTables() {
+		if _, err = mariadb.RunCheck(ctx, c.DBInterface, table.Name, uuid.NewString(), c.DeleteRow, c.timeouts().Stage); err != nil {
+			break
+		}
+	}
+
+	c.Misconfig.note(err, c.MisconfigReadinessOnly)
+
+	if err == nil {
+		if _, err := c.Server.get(ctx, c.DBInterface); err != nil {
+			slog.Warn("failed to detect server", "error", err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAccessDenied = &mysql.MySQLError{Number: 1045, Message: "Access denied for user 'healthcheck'@'127.0.0.1'"}
+
+func TestErrorScopeMisconfiguration(t *testing.T) {
+	t.Run("should fail every probe by default", func(t *testing.T) {
+		assert.Equal(t, health.ScopeAll, config{}.errorScope(mariadb.CategoryAuthentication))
+	})
+
+	t.Run("should only fail readiness in misconfiguration mode", func(t *testing.T) {
+		cfg := config{MisconfigReadinessOnly: true}
+
+		assert.Equal(t, health.ScopeReadiness, cfg.errorScope(mariadb.CategoryAuthentication))
+		assert.Equal(t, health.ScopeReadiness, cfg.errorScope(mariadb.CategoryUnknownTable))
+		assert.Equal(t, health.ScopeAll, cfg.errorScope(mariadb.CategoryNetwork))
+	})
+
+	t.Run("should prefer an explicit error scope", func(t *testing.T) {
+		cfg := config{
+			MisconfigReadinessOnly: true,
+			ErrorScopes:            map[mariadb.ErrorCategory]health.Scope{mariadb.CategoryAuthentication: health.ScopeNone},
+		}
+
+		assert.Equal(t, health.ScopeNone, cfg.errorScope(mariadb.CategoryAuthentication))
+	})
+}
+
+func TestHealthHandlerMisconfiguration(t *testing.T) {
+	t.Run("should keep liveness green on access denied", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(errAccessDenied)
+
+		cfg := config{DBInterface: db, Misconfig: &misconfiguration{}, MisconfigReadinessOnly: true}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=liveness", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "degraded: failed to insert row: authentication", w.Body.String())
+		assert.Equal(t, mariadb.CategoryAuthentication, cfg.Misconfig.category)
+	})
+}
+
+func TestMisconfiguration(t *testing.T) {
+	t.Run("should remember misconfiguration until a round-trip succeeds", func(t *testing.T) {
+		m := &misconfiguration{}
+
+		m.note(errAccessDenied, false)
+		assert.Equal(t, mariadb.CategoryAuthentication, m.category)
+
+		since := m.since
+		m.note(errAccessDenied, false)
+		assert.Equal(t, since, m.since)
+
+		m.note(nil, false)
+		assert.NoError(t, m.err)
+	})
+
+	t.Run("should ignore server faults", func(t *testing.T) {
+		m := &misconfiguration{}
+
+		m.note(context.DeadlineExceeded, false)
+
+		assert.NoError(t, m.err)
+	})
+
+	t.Run("should keep misconfiguration on a server fault", func(t *testing.T) {
+		m := &misconfiguration{}
+
+		m.note(errAccessDenied, false)
+		m.note(context.DeadlineExceeded, false)
+
+		assert.Equal(t, mariadb.CategoryAuthentication, m.category)
+	})
+
+	t.Run("should be a no-op on nil", func(_ *testing.T) {
+		var m *misconfiguration
+
+		m.note(errAccessDenied, true)
+		m.report(true)
+	})
+
+	t.Run("should report periodically until canceled", func(t *testing.T) {
+		cfg := config{Misconfig: &misconfiguration{}}
+		cfg.Misconfig.note(errAccessDenied, false)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		cfg.watchMisconfiguration(ctx, 10*time.Millisecond)
+	})
+}
+
+func TestSelfTest(t *testing.T) {
+	t.Run("should detect misconfiguration at startup", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(errAccessDenied)
+
+		cfg := config{DBInterface: db, Misconfig: &misconfiguration{}}
+		cfg.selfTest(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.True(t, errors.Is(cfg.Misconfig.err, mariadb.ErrInsert))
+	})
+
+	t.Run("should keep the row like probes do", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT uuid FROM status WHERE uuid = ?").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("any-uuid"))
+
+		cfg := config{DBInterface: db, Misconfig: &misconfiguration{}, DeleteRow: false}
+		cfg.selfTest(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.NoError(t, cfg.Misconfig.err)
+	})
+}
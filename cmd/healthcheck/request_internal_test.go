@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandlerModes(t *testing.T) {
+	t.Run("should only ping in ping mode", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectPing()
+
+		w := httptest.NewRecorder()
+		healthHandler(t, config{DBInterface: db}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?mode=ping", nil))
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "OK", w.Body.String())
+	})
+
+	t.Run("should only select in read mode", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT uuid FROM status LIMIT 1").
+			WillReturnError(assert.AnError)
+
+		w := httptest.NewRecorder()
+		healthHandler(t, config{DBInterface: db}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?mode=read", nil))
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "failed to read status table", w.Body.String())
+	})
+
+	t.Run("should run only the requested checks", func(t *testing.T) {
+		saturated := stubCheck{health.Result{Name: "connections", Status: health.StatusUnhealthy, Scope: health.ScopeReadiness, Message: "full"}}
+		cfg := config{
+			DBInterface:     newRoundTripDB(t),
+			AvailableChecks: map[string]health.Checker{"connections": saturated},
+		}
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness&checks=connections", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "connections: full", w.Body.String())
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		healthHandler(t, config{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?mode=deep", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid mode")
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// detectRole reads the role of the server, logging and answering 500 when it
+// cannot be determined. It returns false in that case.
+func (c config) detectRole(w http.ResponseWriter, r *http.Request) (mariadb.Topology, bool) {
+	defer c.Watchdog.Track()()
+
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeouts().Check)
+	defer cancel()
+
+	topology, err := c.detectTopology(ctx)
+	if err != nil {
+		category := mariadb.Classify(err)
+		slog.ErrorContext(ctx, "failed to detect role", "category", category, "error", err)
+
+		message := "failed to detect role"
+		if category != mariadb.CategoryUnknown {
+			message += ": " + string(category)
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		writeBody(w, message)
+
+		return mariadb.Topology{}, false
+	}
+
+	return topology, true
+}
+
+// roleHandler reports the role of the server as plain text, or the whole
+// topology with ?verbose=true.
+func (c config) roleHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	topology, ok := c.detectRole(w, r)
+	if !ok {
+		return
+	}
+
+	if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); verbose {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(topology); err != nil {
+			slog.Error("failed to write body", "error", err)
+		}
+
+		return
+	}
+
+	writeBody(w, string(topology.Role))
+}
+
+// requireRole returns a handler answering 200 only while the server has the
+// given role, so it can back a readiness probe that selects e.g. the current
+// primary for a write Service. Like readiness, it fails while draining or in
+// maintenance without touching the database.
+func (c config) requireRole(role mariadb.Role) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		switch {
+		case c.Draining != nil && c.Draining.Load():
+			w.WriteHeader(http.StatusServiceUnavailable)
+			writeBody(w, "shutting down")
+
+			return
+		case c.Maintenance.Current().Active:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			writeBody(w, "maintenance")
+
+			return
+		}
+
+		topology, ok := c.detectRole(w, r)
+		if !ok {
+			return
+		}
+
+		if topology.Role != role {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		writeBody(w, string(topology.Role))
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRoleDB returns a mock database reporting a non-Galera server with the
+// given read_only value and number of replication channels.
+func newRoleDB(t *testing.T, readOnly string, channels int) *sql.DB {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	replicas := sqlmock.NewRows([]string{"Connection_name"})
+	for range channels {
+		replicas.AddRow("")
+	}
+
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES WHERE Variable_name IN ('read_only', 'wsrep_on')").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("read_only", readOnly))
+	mock.ExpectQuery("SHOW ALL SLAVES STATUS").WillReturnRows(replicas)
+
+	return db
+}
+
+func TestRoleHandler(t *testing.T) {
+	t.Run("should return the role", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		config{DBInterface: newRoleDB(t, "ON", 1)}.roleHandler(w, httptest.NewRequest(http.MethodGet, "/role", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "replica", w.Body.String())
+	})
+
+	t.Run("should return the topology with verbose", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		config{DBInterface: newRoleDB(t, "OFF", 0)}.roleHandler(w, httptest.NewRequest(http.MethodGet, "/role?verbose=true", nil))
+
+		var topology mariadb.Topology
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&topology))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, mariadb.Topology{Role: mariadb.RolePrimary}, topology)
+	})
+
+	t.Run("should return 500 with the category when detection fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL VARIABLES WHERE Variable_name IN ('read_only', 'wsrep_on')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("read_only", "OFF"))
+		mock.ExpectQuery("SHOW ALL SLAVES STATUS").
+			WillReturnError(&mysql.MySQLError{Number: 1227, Message: "Access denied"})
+
+		w := httptest.NewRecorder()
+		config{DBInterface: db}.roleHandler(w, httptest.NewRequest(http.MethodGet, "/role", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "failed to detect role: privileges", w.Body.String())
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	t.Run("should return 200 only for the matching role", func(t *testing.T) {
+		for _, tc := range []struct {
+			role     mariadb.Role
+			readOnly string
+			channels int
+			code     int
+			body     string
+		}{
+			{role: mariadb.RolePrimary, readOnly: "OFF", code: http.StatusOK, body: "primary"},
+			{role: mariadb.RolePrimary, readOnly: "ON", channels: 1, code: http.StatusServiceUnavailable, body: "replica"},
+			{role: mariadb.RolePrimary, readOnly: "ON", code: http.StatusServiceUnavailable, body: "read-only"},
+			{role: mariadb.RoleReplica, readOnly: "ON", channels: 1, code: http.StatusOK, body: "replica"},
+			{role: mariadb.RoleReplica, readOnly: "OFF", code: http.StatusServiceUnavailable, body: "primary"},
+		} {
+			w := httptest.NewRecorder()
+			config{DBInterface: newRoleDB(t, tc.readOnly, tc.channels)}.requireRole(tc.role)(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tc.code, w.Code, tc.role, tc.body)
+			assert.Equal(t, tc.body, w.Body.String(), tc.role)
+		}
+	})
+
+	t.Run("should fail without touching the database while draining", func(t *testing.T) {
+		// No DBInterface: any attempt to reach the database would panic.
+		cfg := config{Draining: &atomic.Bool{}}
+		cfg.Draining.Store(true)
+
+		w := httptest.NewRecorder()
+		cfg.requireRole(mariadb.RolePrimary)(w, httptest.NewRequest(http.MethodGet, "/primary", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "shutting down", w.Body.String())
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// selfHandler reports whether the sidecar itself is alive. It never touches
+// the database, so a MariaDB outage does not get the sidecar restarted and its
+// in-memory state (history, maintenance, health state) lost. It fails when a
+// background loop stopped beating or a probe has been stuck for longer than
+// hungRequestChecks check timeouts, i.e. well past its own context timeout.
+func (c config) selfHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	problems := c.Watchdog.Problems(c.timeouts().Check * hungRequestChecks)
+	if len(problems) == 0 {
+		w.WriteHeader(http.StatusOK)
+		writeBody(w, "OK")
+
+		return
+	}
+
+	slog.Error("sidecar self-check failed", "problems", problems)
+
+	w.WriteHeader(http.StatusServiceUnavailable)
+	writeBody(w, strings.Join(problems, "\n"))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/watchdog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfHandler(t *testing.T) {
+	t.Run("should return OK without touching the database", func(t *testing.T) {
+		// No DBInterface: any attempt to reach the database would panic.
+		server := httptest.NewServer(setupServer(config{Watchdog: watchdog.New()}).Handler)
+		defer server.Close()
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/self", nil)
+		assert.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+
+		body := decodeHTTPBody(t, resp)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "OK", body)
+	})
+
+	t.Run("should fail when a background loop stopped beating", func(t *testing.T) {
+		cfg := config{Watchdog: watchdog.New()}
+		cfg.Watchdog.Register("stuck", time.Nanosecond)
+
+		time.Sleep(time.Millisecond)
+
+		w := httptest.NewRecorder()
+		cfg.selfHandler(w, httptest.NewRequest(http.MethodGet, "/self", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "stuck missed heartbeats")
+	})
+}
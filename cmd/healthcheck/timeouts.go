@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// timeouts bounds the checks, the HTTP servers and the shutdown.
+type timeouts struct {
+	// Check bounds a probe request and every background check.
+	Check time.Duration
+	// Stage bounds a single round-trip stage; zero leaves it to Check.
+	Stage          time.Duration
+	HTTPRead       time.Duration
+	HTTPReadHeader time.Duration
+	HTTPWrite      time.Duration
+	HTTPIdle       time.Duration
+	Shutdown       time.Duration
+	// TerminationGracePeriod is the terminationGracePeriodSeconds of the pod.
+	// It is only used to validate the drain and shutdown timeouts.
+	TerminationGracePeriod time.Duration
+}
+
+// defaultTimeouts returns the timeouts used when none are configured.
+func defaultTimeouts() timeouts {
+	return timeouts{
+		Check:                  defaultCheckTimeout,
+		HTTPRead:               defaultHTTPReadTimeout,
+		HTTPReadHeader:         defaultHTTPReadHeaderTimeout,
+		HTTPWrite:              defaultHTTPWriteTimeout,
+		HTTPIdle:               defaultHTTPIdleTimeout,
+		Shutdown:               defaultShutdownTimeout,
+		TerminationGracePeriod: defaultTerminationGracePeriod,
+	}
+}
+
+// timeouts returns the configured timeouts, or the defaults when none are
+// configured.
+func (c config) timeouts() timeouts {
+	if c.Timeouts == (timeouts{}) {
+		return defaultTimeouts()
+	}
+
+	return c.Timeouts
+}
+
+// validate checks every timeout and how they relate: the response has to be
+// written after the check finished, and the pod has to be stopped gracefully
+// before the kubelet kills it.
+func (t timeouts) validate(drain time.Duration) error {
+	for _, field := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{checkTimeout, t.Check},
+		{httpReadTimeout, t.HTTPRead},
+		{httpReadHeaderTimeout, t.HTTPReadHeader},
+		{httpWriteTimeout, t.HTTPWrite},
+		{httpIdleTimeout, t.HTTPIdle},
+		{shutdownTimeout, t.Shutdown},
+		{terminationGracePeriod, t.TerminationGracePeriod},
+	} {
+		if field.value <= 0 {
+			return fmt.Errorf("%s must be positive", field.name)
+		}
+	}
+
+	if t.Stage < 0 || t.Stage > t.Check {
+		return fmt.Errorf("%s %s must be between 0 and %s %s", stageTimeout, t.Stage, checkTimeout, t.Check)
+	}
+
+	if t.HTTPWrite <= t.Check {
+		return fmt.Errorf("%s %s must be longer than %s %s, or responses are cut off",
+			httpWriteTimeout, t.HTTPWrite, checkTimeout, t.Check)
+	}
+
+	if t.HTTPReadHeader > t.HTTPRead {
+		return fmt.Errorf("%s %s must not be longer than %s %s",
+			httpReadHeaderTimeout, t.HTTPReadHeader, httpReadTimeout, t.HTTPRead)
+	}
+
+	if drain+t.Shutdown >= t.TerminationGracePeriod {
+		return fmt.Errorf("%s %s plus %s %s must be shorter than %s %s, or the sidecar is killed while draining",
+			drainPeriod, drain, shutdownTimeout, t.Shutdown, terminationGracePeriod, t.TerminationGracePeriod)
+	}
+
+	return nil
+}
+
+// parse returns the timeouts, falling back to the defaults.
+func (e timeoutsEnvironment) parse() (timeouts, error) {
+	t := defaultTimeouts()
+
+	for _, field := range []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{checkTimeout, e.Check, &t.Check},
+		{stageTimeout, e.Stage, &t.Stage},
+		{httpReadTimeout, e.HTTPRead, &t.HTTPRead},
+		{httpReadHeaderTimeout, e.HTTPReadHeader, &t.HTTPReadHeader},
+		{httpWriteTimeout, e.HTTPWrite, &t.HTTPWrite},
+		{httpIdleTimeout, e.HTTPIdle, &t.HTTPIdle},
+		{shutdownTimeout, e.Shutdown, &t.Shutdown},
+		{terminationGracePeriod, e.TerminationGracePeriod, &t.TerminationGracePeriod},
+	} {
+		value, err := durationOr(field.value, *field.into)
+		if err != nil {
+			return timeouts{}, fmt.Errorf("failed to parse %s: %w", field.name, err)
+		}
+
+		*field.into = value
+	}
+
+	return t, nil
+}
+
+// parse returns the connection pool, falling back to mariadb.DefaultPool.
+func (e poolEnvironment) parse() (mariadb.Pool, error) {
+	pool := mariadb.DefaultPool()
+
+	var err error
+
+	if pool.ConnectTimeout, err = durationOr(e.ConnectTimeout, pool.ConnectTimeout); err != nil {
+		return mariadb.Pool{}, fmt.Errorf("failed to parse %s: %w", dbConnectTimeout, err)
+	}
+
+	if pool.MaxOpenConns, err = intOr(e.MaxOpenConns, pool.MaxOpenConns); err != nil {
+		return mariadb.Pool{}, fmt.Errorf("failed to parse %s: %w", dbMaxOpenConns, err)
+	}
+
+	if pool.MaxIdleConns, err = intOr(e.MaxIdleConns, pool.MaxIdleConns); err != nil {
+		return mariadb.Pool{}, fmt.Errorf("failed to parse %s: %w", dbMaxIdleConns, err)
+	}
+
+	if pool.ConnMaxLifetime, err = durationOr(e.ConnMaxLifetime, pool.ConnMaxLifetime); err != nil {
+		return mariadb.Pool{}, fmt.Errorf("failed to parse %s: %w", dbConnMaxLifetime, err)
+	}
+
+	if pool.ConnMaxIdleTime, err = durationOr(e.ConnMaxIdleTime, pool.ConnMaxIdleTime); err != nil {
+		return mariadb.Pool{}, fmt.Errorf("failed to parse %s: %w", dbConnMaxIdleTime, err)
+	}
+
+	if err := pool.Validate(); err != nil {
+		return mariadb.Pool{}, err
+	}
+
+	return pool, nil
+}
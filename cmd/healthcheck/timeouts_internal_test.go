@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutsValidate(t *testing.T) {
+	t.Run("should accept the defaults", func(t *testing.T) {
+		assert.NoError(t, defaultTimeouts().validate(defaultDrainPeriod))
+	})
+
+	t.Run("should reject inconsistent timeouts", func(t *testing.T) {
+		for message, change := range map[string]func(*timeouts){
+			"CHECK_TIMEOUT must be positive": func(t *timeouts) { t.Check = 0 },
+			"STAGE_TIMEOUT 6s must be between 0 and CHECK_TIMEOUT 5s": func(t *timeouts) {
+				t.Stage = 6 * time.Second
+			},
+			"HTTP_WRITE_TIMEOUT 5s must be longer than CHECK_TIMEOUT 5s": func(t *timeouts) {
+				t.HTTPWrite = 5 * time.Second
+			},
+			"HTTP_READ_HEADER_TIMEOUT 10s must not be longer than HTTP_READ_TIMEOUT 5s": func(t *timeouts) {
+				t.HTTPReadHeader = 10 * time.Second
+			},
+			"must be shorter than TERMINATION_GRACE_PERIOD 10s": func(t *timeouts) {
+				t.TerminationGracePeriod = 10 * time.Second
+				t.Shutdown = 5 * time.Second
+			},
+		} {
+			timeouts := defaultTimeouts()
+			change(&timeouts)
+
+			assert.ErrorContains(t, timeouts.validate(defaultDrainPeriod), message)
+		}
+	})
+}
+
+func TestTimeoutsEnvironmentParse(t *testing.T) {
+	t.Run("should fall back to the defaults", func(t *testing.T) {
+		timeouts, err := timeoutsEnvironment{}.parse()
+
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeouts(), timeouts)
+	})
+
+	t.Run("should parse every timeout", func(t *testing.T) {
+		timeouts, err := timeoutsEnvironment{Check: "2s", Stage: "500ms", HTTPWrite: "3s", Shutdown: "20s"}.parse()
+
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, timeouts.Check)
+		assert.Equal(t, 500*time.Millisecond, timeouts.Stage)
+		assert.Equal(t, 3*time.Second, timeouts.HTTPWrite)
+		assert.Equal(t, 20*time.Second, timeouts.Shutdown)
+	})
+
+	t.Run("should return error for an invalid duration", func(t *testing.T) {
+		_, err := timeoutsEnvironment{HTTPIdle: "forever"}.parse()
+
+		assert.ErrorContains(t, err, "failed to parse HTTP_IDLE_TIMEOUT")
+	})
+}
+
+func TestPoolEnvironmentParse(t *testing.T) {
+	t.Run("should fall back to the default pool", func(t *testing.T) {
+		pool, err := poolEnvironment{}.parse()
+
+		require.NoError(t, err)
+		assert.Equal(t, mariadb.DefaultPool(), pool)
+	})
+
+	t.Run("should parse the pool", func(t *testing.T) {
+		pool, err := poolEnvironment{MaxOpenConns: "4", MaxIdleConns: "2", ConnMaxLifetime: "1h"}.parse()
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, pool.MaxOpenConns)
+		assert.Equal(t, 2, pool.MaxIdleConns)
+		assert.Equal(t, time.Hour, pool.ConnMaxLifetime)
+	})
+
+	t.Run("should return error for an invalid pool", func(t *testing.T) {
+		for env, message := range map[poolEnvironment]string{
+			{MaxOpenConns: "many"}:                 "failed to parse DB_MAX_OPEN_CONNS",
+			{ConnectTimeout: "-1s"}:                "failed to parse DB_CONNECT_TIMEOUT",
+			{MaxOpenConns: "1", MaxIdleConns: "2"}: "invalid max idle connections 2",
+		} {
+			_, err := env.parse()
+
+			assert.ErrorContains(t, err, message)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/certs"
+)
+
+// tlsListener serves HTTPS with a certificate reloaded from files.
+type tlsListener struct {
+	Certificate *certs.Reloader
+	// ClientCAs verifies client certificates; nil does not ask for one.
+	ClientCAs *x509.CertPool
+	// Port serves TLS next to the plain HEALTH_PORT, e.g. for scrapers using
+	// mTLS while the kubelet keeps probing over plain HTTP. Zero serves TLS on
+	// HEALTH_PORT instead.
+	Port int
+}
+
+// config returns the TLS configuration of the listener.
+func (t *tlsListener) config() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: t.Certificate.GetCertificate,
+	}
+
+	if t.ClientCAs != nil {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = t.ClientCAs
+	}
+
+	return cfg
+}
+
+// parse returns the TLS listener, or nil when no certificate is configured.
+func (e tlsEnvironment) parse() (*tlsListener, error) {
+	if e.CertFile == "" && e.KeyFile == "" {
+		if e.ClientCAFile != "" || e.Port != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE and TLS_PORT require TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+
+		return nil, nil
+	}
+
+	if e.CertFile == "" || e.KeyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	reloader, err := certs.NewReloader(e.CertFile, e.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	port, err := intOr(e.Port, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLSPort: %w", err)
+	}
+
+	// The kubelet cannot present a client certificate, so mTLS on HEALTH_PORT
+	// would fail every probe.
+	if e.ClientCAFile != "" && port == 0 {
+		return nil, errors.New("TLS_CLIENT_CA_FILE requires TLS_PORT, the kubelet cannot probe an mTLS HEALTH_PORT")
+	}
+
+	listener := &tlsListener{Certificate: reloader, Port: port}
+
+	if e.ClientCAFile != "" {
+		pool, err := certs.LoadCAPool(e.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client CA: %w", err)
+		}
+
+		listener.ClientCAs = pool
+	}
+
+	return listener, nil
+}
+
+// servers returns the listeners to start: the plain HEALTH_PORT, served over
+// TLS when a certificate is configured without TLS_PORT, the TLS_PORT listener
+// when one is configured, and the admin listener, served over TLS whenever a
+// certificate is configured.
+func (c config) servers() []*http.Server {
+	server := setupServer(c)
+	servers := []*http.Server{server}
+
+	if c.TLS != nil && c.TLS.Port == 0 {
+		server.TLSConfig = c.TLS.config()
+	}
+
+	if c.TLS != nil && c.TLS.Port != 0 {
+		secure := setupServer(c)
+		secure.Addr = fmt.Sprintf(":%d", c.TLS.Port)
+		secure.TLSConfig = c.TLS.config()
+
+		servers = append(servers, secure)
+	}
+
+	if admin := setupAdminServer(c); admin != nil {
+		if c.TLS != nil {
+			admin.TLSConfig = c.TLS.config()
+		}
+
+		servers = append(servers, admin)
+	}
+
+	return servers
+}
+
+// watchCertificate reloads the TLS certificate every interval, so a renewed
+// Secret is served without restarting the pod. It does nothing when TLS is
+// disabled.
+func (c config) watchCertificate(ctx context.Context, interval time.Duration) {
+	if c.TLS == nil {
+		return
+	}
+
+	const name = "certificate-reloader"
+
+	c.Watchdog.Register(name, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := c.TLS.Certificate.Reload()
+
+		switch {
+		case err != nil:
+			slog.Error("failed to reload certificate, keeping the previous one", "error", err)
+		case reloaded:
+			slog.Info("reloaded certificate")
+		}
+
+		c.Watchdog.Beat(name)
+	}
+}
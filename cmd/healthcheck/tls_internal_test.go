@@ -0,0 +1,240 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/watchdog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issued is a certificate with its key, written to PEM files.
+type issued struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// issue creates a certificate for name signed by parent, or self-signed when
+// parent is nil, and writes it to dir.
+func issue(t *testing.T, dir, name string, parent *issued) issued {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	result := issued{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+
+	require.NoError(t, os.WriteFile(result.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(result.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return result
+}
+
+func TestTLSEnvironmentParse(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+
+	t.Run("should disable TLS without a certificate", func(t *testing.T) {
+		listener, err := tlsEnvironment{}.parse()
+
+		require.NoError(t, err)
+		assert.Nil(t, listener)
+	})
+
+	t.Run("should load the certificate and the client CA", func(t *testing.T) {
+		listener, err := tlsEnvironment{
+			CertFile:     server.certFile,
+			KeyFile:      server.keyFile,
+			ClientCAFile: ca.certFile,
+			Port:         "8443",
+		}.parse()
+
+		require.NoError(t, err)
+		assert.Equal(t, 8443, listener.Port)
+		assert.NotNil(t, listener.ClientCAs)
+		assert.Equal(t, tls.RequireAndVerifyClientCert, listener.config().ClientAuth)
+	})
+
+	t.Run("should return error for incomplete settings", func(t *testing.T) {
+		for env, message := range map[tlsEnvironment]string{
+			{CertFile: server.certFile}:                                                                      "must be set together",
+			{ClientCAFile: ca.certFile}:                                                                      "require TLS_CERT_FILE",
+			{CertFile: server.certFile, KeyFile: ca.keyFile}:                                                 "failed to load certificate",
+			{CertFile: server.certFile, KeyFile: server.keyFile, Port: "https"}:                              "failed to parse TLSPort",
+			{CertFile: server.certFile, KeyFile: server.keyFile, ClientCAFile: ca.certFile}:                  "requires TLS_PORT",
+			{CertFile: server.certFile, KeyFile: server.keyFile, ClientCAFile: "/nonexistent", Port: "8443"}: "failed to load client CA",
+		} {
+			_, err := env.parse()
+
+			assert.ErrorContains(t, err, message)
+		}
+	})
+}
+
+func TestServers(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+
+	listener, err := tlsEnvironment{CertFile: server.certFile, KeyFile: server.keyFile}.parse()
+	require.NoError(t, err)
+
+	t.Run("should serve plain HTTP without TLS", func(t *testing.T) {
+		servers := config{HealthPort: 8080}.servers()
+
+		require.Len(t, servers, 1)
+		assert.Nil(t, servers[0].TLSConfig)
+	})
+
+	t.Run("should serve TLS on the health port", func(t *testing.T) {
+		servers := config{HealthPort: 8080, TLS: listener}.servers()
+
+		require.Len(t, servers, 1)
+		assert.Equal(t, ":8080", servers[0].Addr)
+		assert.NotNil(t, servers[0].TLSConfig)
+	})
+
+	t.Run("should keep plain HTTP next to a TLS port", func(t *testing.T) {
+		split := *listener
+		split.Port = 8443
+
+		servers := config{HealthPort: 8080, TLS: &split}.servers()
+
+		require.Len(t, servers, 2)
+		assert.Equal(t, ":8080", servers[0].Addr)
+		assert.Nil(t, servers[0].TLSConfig)
+		assert.Equal(t, ":8443", servers[1].Addr)
+		assert.NotNil(t, servers[1].TLSConfig)
+	})
+
+	t.Run("should add the admin listener", func(t *testing.T) {
+		servers := config{HealthPort: 8080, AdminListen: "127.0.0.1:9090", TLS: listener}.servers()
+
+		require.Len(t, servers, 2)
+		assert.Equal(t, "127.0.0.1:9090", servers[1].Addr)
+		assert.NotNil(t, servers[1].TLSConfig)
+	})
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	server := issue(t, dir, "server", &ca)
+	client := issue(t, dir, "client", &ca)
+	stranger := issue(t, dir, "stranger", nil)
+
+	listener, err := tlsEnvironment{
+		CertFile:     server.certFile,
+		KeyFile:      server.keyFile,
+		ClientCAFile: ca.certFile,
+		Port:         "8443",
+	}.parse()
+	require.NoError(t, err)
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := setupServer(config{Watchdog: watchdog.New()})
+	srv.TLSConfig = listener.config()
+
+	go func() { _ = srv.ServeTLS(tcp, "", "") }()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	get := func(t *testing.T, cert *issued) (*http.Response, error) {
+		t.Helper()
+
+		tlsConfig := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+		if cert != nil {
+			pair, err := tls.LoadX509KeyPair(cert.certFile, cert.keyFile)
+			require.NoError(t, err)
+
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://"+tcp.Addr().String()+"/self", nil)
+		require.NoError(t, err)
+
+		return httpClient.Do(req)
+	}
+
+	t.Run("should accept a client certificate signed by the CA", func(t *testing.T) {
+		resp, err := get(t, &client)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should reject a client without a certificate", func(t *testing.T) {
+		resp, err := get(t, nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject a client certificate from another CA", func(t *testing.T) {
+		resp, err := get(t, &stranger)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		assert.Error(t, err)
+	})
+}
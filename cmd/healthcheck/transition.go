@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+)
+
+// probeState is how the last full probe of a scope saw the database.
+type probeState struct {
+	status      health.Status
+	reason      string
+	eventReason string
+}
+
+// probeStates remembers the last full probe per scope, so liveness and
+// readiness probes judging the same failure differently do not flip the
+// aggregated state back and forth. All methods on a nil *probeStates only
+// consider the latest probe.
+type probeStates struct {
+	mu     sync.Mutex
+	states map[health.Scope]probeState
+}
+
+// aggregate records state for scope and returns the worst state over every
+// scope seen so far, readiness first on a tie so the reason is stable.
+func (p *probeStates) aggregate(scope health.Scope, state probeState) probeState {
+	if p == nil {
+		return state
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.states == nil {
+		p.states = map[health.Scope]probeState{}
+	}
+
+	p.states[scope] = state
+
+	worst := probeState{status: health.StatusUnknown}
+
+	for _, s := range []health.Scope{health.ScopeReadiness, health.ScopeLiveness} {
+		if seen, ok := p.states[s]; ok && seen.status > worst.status {
+			worst = seen
+		}
+	}
+
+	return worst
+}
+
+// observe feeds the outcome of a probe into the state tracker and notifies
+// the webhook receivers when the aggregated state changes. Only full probes
+// count, as a ping or a request picking its own checks does not see
+// everything; each is judged by the scope of its probe. Only transitions are
+// forwarded; pod Events are also created when the cause of a failure changes.
+func (c config) observe(outcome healthcheck.Outcome) {
+	if !outcome.Full {
+		return
+	}
+
+	scope := outcome.Probe.Scope()
+	report := outcome.Report
+	state := probeState{status: report.Status(scope)}
+
+	worst, failing := report.Worst(scope)
+	if failing {
+		state.reason = worst.Name + ": " + worst.Message
+	}
+
+	state.eventReason = eventReason(worst, failing, outcome.ErrOf(worst.Name))
+	state = c.Probes.aggregate(scope, state)
+
+	c.Events.observe(state.status, state.eventReason, state.reason)
+
+	transition, changed := c.Health.Observe(state.status, state.reason)
+	if !changed {
+		return
+	}
+
+	slog.Info(
+		"health state changed",
+		"from", transition.From,
+		"to", transition.To,
+		"reason", transition.Reason,
+	)
+
+	c.Notifier.Notify(transition)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/webhook"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	t.Run("should notify only on transitions", func(t *testing.T) {
+		bodies := make(chan string, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- string(body)
+		}))
+		defer server.Close()
+
+		notifier, err := webhook.New(webhook.Config{
+			URLs:    []string{server.URL},
+			Format:  webhook.FormatCloudEvents,
+			Timeout: time.Second,
+		})
+		require.NoError(t, err)
+
+		go notifier.Run(t.Context())
+
+		cfg := config{Health: health.NewTracker(), Notifier: notifier}
+
+		passed := healthcheck.Outcome{Full: true, Report: health.Report{Results: []health.Result{
+			{Name: healthcheck.CheckRoundTrip, Status: health.StatusHealthy, Scope: health.ScopeAll},
+		}}}
+		failed := healthcheck.Outcome{
+			Full: true,
+			Report: health.Report{Results: []health.Result{
+				{Name: healthcheck.CheckRoundTrip, Status: health.StatusUnhealthy, Scope: health.ScopeAll, Message: "failed to insert row"},
+			}},
+			RoundTrips: []healthcheck.RoundTrip{
+				{Name: healthcheck.CheckRoundTrip, Scope: health.ScopeAll, Err: errors.New("failed to insert row")},
+			},
+		}
+
+		cfg.observe(passed)
+		cfg.observe(failed)
+		cfg.observe(failed)
+
+		select {
+		case body := <-bodies:
+			assert.Contains(t, body, `"to":"unhealthy"`)
+		case <-time.After(2 * time.Second):
+			t.Fatal("transition was not delivered")
+		}
+
+		select {
+		case <-bodies:
+			t.Fatal("repeated failure must not be delivered")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		assert.Equal(t, health.StatusUnhealthy, cfg.Health.Current())
+	})
+	t.Run("should ignore probes asking for less", func(t *testing.T) {
+		cfg := config{Health: health.NewTracker()}
+
+		cfg.observe(healthcheck.Outcome{Report: health.Report{Results: []health.Result{
+			{Name: healthcheck.CheckRoundTrip, Status: health.StatusUnhealthy, Scope: health.ScopeAll},
+		}}})
+
+		assert.Equal(t, health.StatusUnknown, cfg.Health.Current())
+	})
+
+	t.Run("should not flip between liveness and readiness", func(t *testing.T) {
+		cfg := config{Health: health.NewTracker(), Probes: &probeStates{}}
+		report := health.Report{Results: []health.Result{
+			{Name: healthcheck.CheckRoundTrip, Status: health.StatusHealthy, Scope: health.ScopeAll},
+			{Name: "connections", Status: health.StatusUnhealthy, Scope: health.ScopeReadiness, Message: "full"},
+		}}
+
+		for _, probe := range []healthcheck.Probe{healthcheck.ProbeReadiness, healthcheck.ProbeLiveness, healthcheck.ProbeReadiness} {
+			cfg.observe(healthcheck.Outcome{Probe: probe, Full: true, Report: report})
+
+			assert.Equal(t, health.StatusUnhealthy, cfg.Health.Current(), probe)
+		}
+	})
+}
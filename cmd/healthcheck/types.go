@@ -2,21 +2,205 @@ package main
 
 import (
 	"database/sql"
+	"net/netip"
+	"os"
+	"sync/atomic"
+	"time"
 
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/richie-tt/mariadb-healthcheck/internal/maintenance"
 	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/richie-tt/mariadb-healthcheck/internal/metrics"
+	"github.com/richie-tt/mariadb-healthcheck/internal/watchdog"
+	"github.com/richie-tt/mariadb-healthcheck/internal/webhook"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
 )
 
 type environment struct {
-	DeleteRow  string
-	Connection mariadb.Connection
-	HealthPort string
-	LogLevel   string
+	AdminAllow         string
+	AdminBasicAuthFile string
+	AdminListen        string
+	AdminToken         string
+	AdminTokenFile     string
+	Checks             string
+	Connections        connectionsEnvironment
+	DeleteRow          string
+	DrainPeriod        string
+	ErrorScopes        string
+	Connection         mariadb.Connection
+	HealthListen       string
+	HealthPort         string
+	HealthSocketMode   string
+	HistorySize        string
+	InnoDB             innodbEnvironment
+	Kube               kubeEnvironment
+	LogLevel           string
+	MaintenanceTTL     string
+	Metrics            metricsEnvironment
+	MinVersion         string
+	Pool               poolEnvironment
+	Misconfig          string
+	Resources          resourcesEnvironment
+	SemiSync           semiSyncEnvironment
+	StatusTables       string
+	Timeouts           timeoutsEnvironment
+	TLS                tlsEnvironment
+	Transactions       transactionsEnvironment
+	Webhook            webhookEnvironment
+}
+
+type connectionsEnvironment struct {
+	DegradedPercent  string
+	UnhealthyPercent string
+}
+
+type innodbEnvironment struct {
+	HistoryLength        string
+	CheckpointAgePercent string
+	PendingIO            string
+	BufferPoolHitPercent string
+	Deadlocks            string
+}
+
+type metricsEnvironment struct {
+	Enabled   string
+	Status    string
+	Variables string
+}
+
+type resourcesEnvironment struct {
+	OpenFilesPercent        string
+	OpenedTablesPerSecond   string
+	ThreadsCreatedPerSecond string
+	TmpDiskTablesPercent    string
+}
+
+type semiSyncEnvironment struct {
+	MinClients string
+}
+
+type poolEnvironment struct {
+	ConnectTimeout  string
+	MaxOpenConns    string
+	MaxIdleConns    string
+	ConnMaxLifetime string
+	ConnMaxIdleTime string
+}
+
+type timeoutsEnvironment struct {
+	Check                  string
+	Stage                  string
+	HTTPRead               string
+	HTTPReadHeader         string
+	HTTPWrite              string
+	HTTPIdle               string
+	Shutdown               string
+	TerminationGracePeriod string
+}
+
+type tlsEnvironment struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	Port         string
+}
+
+type transactionsEnvironment struct {
+	MaxAge      string
+	MaxLockWait string
+	ShowQueries string
+}
+
+type kubeEnvironment struct {
+	Events            string
+	EventsMinInterval string
+	PodName           string
+	PodNamespace      string
+	PodUID            string
+	RoleLabel         string
+	RoleLabelInterval string
+}
+
+type webhookEnvironment struct {
+	URLs        string
+	Format      string
+	Secret      string
+	MaxRetries  string
+	MinInterval string
 }
 
 type config struct {
+	// AdminAllow limits the admin listener to these addresses; empty admits
+	// everyone.
+	AdminAllow []netip.Prefix
+	// AdminListen is the address of the admin listener; empty serves the
+	// admin endpoints on HEALTH_PORT.
+	AdminListen string
+	AdminToken  string
+	// AdminUsers maps basic auth users to their passwords.
+	AdminUsers map[string]string
+	// AvailableChecks holds every optional check by name, for ?checks=.
+	AvailableChecks map[string]health.Checker
+	// Checks are the optional checks run when a request does not pick any.
+	Checks      []health.Checker
 	Connection  mariadb.Connection
 	DBInterface *sql.DB
 	DeleteRow   bool
-	HealthPort  int
+	// Draining is set once shutdown has started; readiness fails from then on.
+	Draining    *atomic.Bool
+	DrainPeriod time.Duration
+	// Events records Kubernetes Events on the pod; nil when disabled.
+	Events *podEvents
+	// ErrorScopes overrides which probes a round-trip failure counts against,
+	// per error category.
+	ErrorScopes map[mariadb.ErrorCategory]health.Scope
+	// HealthListen replaces HealthPort with a TCP host:port or a unix://
+	// socket path.
+	HealthListen string
+	HealthPort   int
+	// HealthSocketMode is the permission of the HealthListen socket.
+	HealthSocketMode os.FileMode
+	Health           *health.Tracker
+	// HealthCheck serves /health; it is built in run once the database is
+	// connected.
+	HealthCheck *healthcheck.Handler
+	History     *history.Ring
+	// Kube talks to the Kubernetes API on behalf of the pod; nil when no
+	// Kubernetes integration is enabled.
+	Kube        *kube.Client
 	LogLevel    string
+	Maintenance *maintenance.Mode
+	// MinServerVersion fails readiness for older servers; nil disables it.
+	MinServerVersion *mariadb.Version
+	// MaintenanceTTL is the expiry applied when maintenance is enabled
+	// without an explicit ttl; zero means no expiry.
+	MaintenanceTTL time.Duration
+	// Metrics re-exports server status at /metrics; nil when disabled.
+	Metrics   *metrics.Exporter
+	Misconfig *misconfiguration
+	// MisconfigReadinessOnly keeps liveness green when the round-trip fails
+	// because of the sidecar's own configuration.
+	MisconfigReadinessOnly bool
+	Notifier               *webhook.Notifier
+	// Probes remembers the last full probe per scope for the aggregated
+	// state.
+	Probes *probeStates
+	// RoleLabel is the pod label kept in sync with the server role; empty
+	// disables labeling.
+	RoleLabel         string
+	RoleLabelInterval time.Duration
+	// Server remembers the version and flavor of the server.
+	Server *serverInfo
+	// StatusTables are the tables the round-trip runs against; empty means
+	// the default status table counting against every probe.
+	StatusTables []healthcheck.Table
+	// Timeouts bound the checks, the HTTP servers and the shutdown; read them
+	// with timeouts(), which falls back to the defaults.
+	Timeouts timeouts
+	// TLS serves HTTPS, optionally with client certificates; nil serves
+	// plain HTTP only.
+	TLS      *tlsListener
+	Watchdog *watchdog.Watchdog
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// checkVersion is the name of the MIN_SERVER_VERSION gate.
+const checkVersion = "version"
+
+// serverInfo remembers the server detected on the first successful connect.
+// A nil *serverInfo never queries and reports the zero mariadb.Server, which
+// picks the MariaDB syntax.
+type serverInfo struct {
+	mu     sync.Mutex
+	server *mariadb.Server
+}
+
+// get returns the remembered server, detecting it first when needed.
+func (s *serverInfo) get(ctx context.Context, db health.DB) (mariadb.Server, error) {
+	if s == nil {
+		return mariadb.Server{}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return *s.server, nil
+	}
+
+	server, err := mariadb.DetectServer(ctx, db)
+	if err != nil {
+		return mariadb.Server{}, err
+	}
+
+	slog.Info(
+		"detected server",
+		"version", server.Version,
+		"flavor", server.Flavor,
+		"server_id", server.ServerID,
+		"hostname", server.Hostname,
+	)
+
+	s.server = &server
+
+	return server, nil
+}
+
+// forget drops the remembered server so the next get detects it again. It is
+// called when a round-trip fails, as the server may be restarting with a new
+// version.
+func (s *serverInfo) forget() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.server = nil
+}
+
+// sidecarVersion is the build information of the sidecar.
+type sidecarVersion struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// versionResponse is the body of /version.
+type versionResponse struct {
+	Sidecar sidecarVersion  `json:"sidecar"`
+	Server  *mariadb.Server `json:"server,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// versionHandler serves the sidecar build and the server identity as JSON.
+// A server that cannot be queried is reported in the error field with status
+// 200, so the sidecar version can always be read.
+func (c config) versionHandler(w http.ResponseWriter, r *http.Request) {
+	defer c.Watchdog.Track()()
+
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeouts().Check)
+	defer cancel()
+
+	body := versionResponse{Sidecar: sidecarVersion{Version: Version, Commit: Commit, BuildDate: BuildDate}}
+
+	server, err := c.Server.get(ctx, c.DBInterface)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to detect server", "error", err)
+		body.Error = "failed to detect server: " + string(mariadb.Classify(err))
+	} else {
+		body.Server = &server
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("failed to write body", "error", err)
+	}
+}
+
+// versionGate fails readiness when the server is older than
+// MIN_SERVER_VERSION, e.g. to keep a pod that was not upgraded yet out of
+// rotation.
+func (c config) versionGate(ctx context.Context) health.Result {
+	result := health.Result{Name: checkVersion, Status: health.StatusHealthy, Scope: health.ScopeReadiness}
+
+	server, err := c.Server.get(ctx, c.DBInterface)
+	if err != nil {
+		result.Status = health.StatusUnhealthy
+		result.Message = err.Error()
+
+		return result
+	}
+
+	result.Details = map[string]any{"version": server.Version, "flavor": server.Flavor}
+
+	if !server.AtLeast(*c.MinServerVersion) {
+		result.Status = health.StatusUnhealthy
+		result.Message = fmt.Sprintf("server version %s is older than the required %s",
+			server.Number(), c.MinServerVersion)
+	}
+
+	return result
+}
+
+// detectTopology detects the role of the server with the syntax of its
+// flavor.
+func (c config) detectTopology(ctx context.Context) (mariadb.Topology, error) {
+	server, err := c.Server.get(ctx, c.DBInterface)
+	if err != nil {
+		return mariadb.Topology{}, err
+	}
+
+	return mariadb.DetectRole(ctx, c.DBInterface, server)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverQuery = "SELECT VERSION(), @@version_comment, @@server_id, @@hostname"
+
+func serverRows(version string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"VERSION()", "@@version_comment", "@@server_id", "@@hostname"}).
+		AddRow(version, "mariadb.org binary distribution", 1, "mariadb-0")
+}
+
+func TestVersionHandler(t *testing.T) {
+	t.Run("should serve the sidecar and server versions and remember the server", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(serverQuery).WillReturnRows(serverRows("11.4.2-MariaDB"))
+
+		cfg := config{DBInterface: db, Server: &serverInfo{}}
+
+		var body versionResponse
+
+		for range 2 {
+			w := httptest.NewRecorder()
+			cfg.versionHandler(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		}
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		require.NotNil(t, body.Server)
+		assert.Equal(t, "11.4.2-MariaDB", body.Server.Version)
+		assert.Equal(t, mariadb.FlavorMariaDB, body.Server.Flavor)
+		assert.Empty(t, body.Error)
+	})
+
+	t.Run("should report a server that cannot be queried", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(serverQuery).WillReturnError(errors.New("gone"))
+
+		w := httptest.NewRecorder()
+		config{DBInterface: db, Server: &serverInfo{}}.versionHandler(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+		var body versionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, body.Server)
+		assert.Equal(t, "failed to detect server: unknown", body.Error)
+	})
+}
+
+func TestVersionGate(t *testing.T) {
+	newConfig := func(t *testing.T, version string) config {
+		t.Helper()
+
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT uuid FROM status WHERE uuid = ?").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("any-uuid"))
+		mock.ExpectQuery(serverQuery).WillReturnRows(serverRows(version))
+
+		return config{
+			DBInterface:      db,
+			Server:           &serverInfo{},
+			MinServerVersion: &mariadb.Version{Major: 10, Minor: 11},
+		}
+	}
+
+	t.Run("should fail readiness for an older server", func(t *testing.T) {
+		cfg := newConfig(t, "10.6.18-MariaDB")
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "version: server version 10.6.18 is older than the required 10.11.0", w.Body.String())
+	})
+
+	t.Run("should pass a new enough server", func(t *testing.T) {
+		cfg := newConfig(t, "11.4.2-MariaDB")
+		w := httptest.NewRecorder()
+
+		healthHandler(t, cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "OK", w.Body.String())
+	})
+}
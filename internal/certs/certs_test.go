@@ -0,0 +1,147 @@
+package certs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/certs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate for name and its key to dir and
+// dates both files at modTime.
+func writeCert(t *testing.T, dir, name string, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, r *certs.Reloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should serve the loaded certificate", func(t *testing.T) {
+		certFile, keyFile := writeCert(t, t.TempDir(), "first", modTime)
+
+		r, err := certs.NewReloader(certFile, keyFile)
+
+		require.NoError(t, err)
+		assert.Equal(t, "first", commonName(t, r))
+	})
+
+	t.Run("should not reload unchanged files", func(t *testing.T) {
+		certFile, keyFile := writeCert(t, t.TempDir(), "first", modTime)
+
+		r, err := certs.NewReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		reloaded, err := r.Reload()
+
+		require.NoError(t, err)
+		assert.False(t, reloaded)
+	})
+
+	t.Run("should reload renewed files", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeCert(t, dir, "first", modTime)
+
+		r, err := certs.NewReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		writeCert(t, dir, "second", modTime.Add(time.Minute))
+
+		reloaded, err := r.Reload()
+
+		require.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.Equal(t, "second", commonName(t, r))
+	})
+
+	t.Run("should keep the previous certificate when the new one is broken", func(t *testing.T) {
+		certFile, keyFile := writeCert(t, t.TempDir(), "first", modTime)
+
+		r, err := certs.NewReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+
+		_, err = r.Reload()
+
+		require.Error(t, err)
+		assert.Equal(t, "first", commonName(t, r))
+	})
+
+	t.Run("should fail for a missing key", func(t *testing.T) {
+		certFile, _ := writeCert(t, t.TempDir(), "first", modTime)
+
+		_, err := certs.NewReloader(certFile, filepath.Join(t.TempDir(), "missing.key"))
+
+		assert.ErrorContains(t, err, "failed to stat")
+	})
+}
+
+func TestLoadCAPool(t *testing.T) {
+	t.Run("should load a PEM certificate", func(t *testing.T) {
+		certFile, _ := writeCert(t, t.TempDir(), "ca", time.Now())
+
+		pool, err := certs.LoadCAPool(certFile)
+
+		require.NoError(t, err)
+		assert.NotNil(t, pool)
+	})
+
+	t.Run("should reject a file without certificates", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(file, []byte("garbage"), 0o600))
+
+		_, err := certs.LoadCAPool(file)
+
+		assert.ErrorContains(t, err, "no PEM certificate")
+	})
+}
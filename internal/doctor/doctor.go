@@ -0,0 +1,367 @@
+// Package doctor diagnoses a healthcheck installation step by step and
+// explains how to fix what it finds.
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// Status is the outcome of a single diagnostic.
+type Status string
+
+// Diagnostic outcomes. A skipped diagnostic depends on one that failed.
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Names of the diagnostics, in the order they run.
+const (
+	CheckDNS            = "dns"
+	CheckTCP            = "tcp"
+	CheckAuthentication = "authentication"
+	CheckGrants         = "grants"
+	CheckTable          = "table"
+	CheckServer         = "server"
+)
+
+// minUUIDLength is the shortest uuid column that holds a UUID.
+const minUUIDLength = 36
+
+// grant matches a line of SHOW GRANTS, capturing the privileges and the
+// object they apply to.
+var grant = regexp.MustCompile("^GRANT (.+) ON (\\S+) TO ")
+
+// Finding is the outcome of a diagnostic, with a hint when something is
+// wrong.
+type Finding struct {
+	Check   string
+	Status  Status
+	Message string
+	Hint    string
+}
+
+// Doctor runs the diagnostics for a connection.
+type Doctor struct {
+	Connection mariadb.Connection
+	// DB is opened from Connection; it is only used once TCP succeeded.
+	DB *sql.DB
+	// Tables are the status tables the round-trip uses.
+	Tables []string
+	// Timeout bounds DNS resolution and the TCP connection.
+	Timeout time.Duration
+}
+
+// Run runs every diagnostic in order. Diagnostics following a failed one are
+// reported as skipped, as they would only repeat its error.
+func (d Doctor) Run(ctx context.Context) []Finding {
+	var findings []Finding
+
+	for _, step := range []struct {
+		name string
+		run  func(context.Context) []Finding
+	}{
+		{CheckDNS, d.dns},
+		{CheckTCP, d.tcp},
+		{CheckAuthentication, d.authentication},
+		{CheckGrants, d.grants},
+		{CheckTable, d.tables},
+		{CheckServer, d.server},
+	} {
+		if Failed(findings) {
+			findings = append(findings, Finding{Check: step.name, Status: StatusSkip})
+			continue
+		}
+
+		findings = append(findings, step.run(ctx)...)
+	}
+
+	return findings
+}
+
+// Failed reports whether any finding failed.
+func Failed(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Status == StatusFail })
+}
+
+// Write prints a human-readable report of the findings.
+func Write(w io.Writer, findings []Finding) error {
+	var b strings.Builder
+
+	for _, f := range findings {
+		fmt.Fprintf(&b, "[%-4s] %s", strings.ToUpper(string(f.Status)), f.Check)
+
+		if f.Message != "" {
+			b.WriteString(": " + f.Message)
+		}
+
+		b.WriteString("\n")
+
+		if f.Hint != "" {
+			b.WriteString("       hint: " + f.Hint + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (d Doctor) dns(ctx context.Context) []Finding {
+	host := d.Connection.Host
+
+	if net.ParseIP(host) != nil {
+		return []Finding{{Check: CheckDNS, Status: StatusOK, Message: host + " is an IP address"}}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return []Finding{{
+			Check:   CheckDNS,
+			Status:  StatusFail,
+			Message: err.Error(),
+			Hint:    "check DB_HOST; in a sidecar the database is usually reachable at 127.0.0.1",
+		}}
+	}
+
+	return []Finding{{Check: CheckDNS, Status: StatusOK, Message: host + " resolves to " + strings.Join(addrs, ", ")}}
+}
+
+func (d Doctor) tcp(ctx context.Context) []Finding {
+	addr := net.JoinHostPort(d.Connection.Host, d.Connection.Port)
+	dialer := net.Dialer{Timeout: d.Timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return []Finding{{
+			Check:   CheckTCP,
+			Status:  StatusFail,
+			Message: err.Error(),
+			Hint:    "check DB_PORT, that MariaDB is running and that bind-address allows " + d.Connection.Host,
+		}}
+	}
+
+	conn.Close()
+
+	return []Finding{{Check: CheckTCP, Status: StatusOK, Message: addr + " accepts connections"}}
+}
+
+func (d Doctor) authentication(ctx context.Context) []Finding {
+	err := d.DB.PingContext(ctx)
+	if err == nil {
+		return []Finding{{
+			Check:   CheckAuthentication,
+			Status:  StatusOK,
+			Message: fmt.Sprintf("logged in as %s to database %s", d.Connection.User, d.Connection.Database),
+		}}
+	}
+
+	finding := Finding{Check: CheckAuthentication, Status: StatusFail, Message: err.Error()}
+
+	switch mariadb.Classify(err) {
+	case mariadb.CategoryAuthentication:
+		finding.Hint = fmt.Sprintf("check DB_USER and DB_PASSWORD, and that the user is created for the host "+
+			"the sidecar connects from, e.g. CREATE USER '%s'@'127.0.0.1' IDENTIFIED BY '...'", d.Connection.User)
+	case mariadb.CategoryUnknownDatabase:
+		finding.Hint = fmt.Sprintf("create the database: CREATE DATABASE `%s`", d.Connection.Database)
+	case mariadb.CategoryPrivileges:
+		finding.Hint = fmt.Sprintf("grant access to the database: GRANT SELECT, INSERT, DELETE ON `%s`.* TO '%s'@'127.0.0.1'",
+			d.Connection.Database, d.Connection.User)
+	case mariadb.CategoryTooManyConnections:
+		finding.Hint = "the server is out of connections; raise max_connections or retry later"
+	}
+
+	return []Finding{finding}
+}
+
+func (d Doctor) grants(ctx context.Context) []Finding {
+	rows, err := d.DB.QueryContext(ctx, "SHOW GRANTS")
+	if err != nil {
+		return []Finding{{Check: CheckGrants, Status: StatusFail, Message: err.Error()}}
+	}
+	defer rows.Close()
+
+	var lines []string
+
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return []Finding{{Check: CheckGrants, Status: StatusFail, Message: err.Error()}}
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []Finding{{Check: CheckGrants, Status: StatusFail, Message: err.Error()}}
+	}
+
+	var findings []Finding
+
+	for _, table := range d.Tables {
+		var missing []string
+
+		for _, privilege := range []string{"SELECT", "INSERT", "DELETE"} {
+			if !granted(lines, privilege, d.Connection.Database, table) {
+				missing = append(missing, privilege)
+			}
+		}
+
+		if len(missing) == 0 {
+			findings = append(findings, Finding{
+				Check:   CheckGrants,
+				Status:  StatusOK,
+				Message: "SELECT, INSERT and DELETE granted on " + table,
+			})
+
+			continue
+		}
+
+		// Privileges granted through a role, a wildcard database or on columns
+		// are not recognised, so this is only a warning and the table check
+		// still runs.
+		findings = append(findings, Finding{
+			Check:   CheckGrants,
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s not found in SHOW GRANTS for %s", strings.Join(missing, ", "), table),
+			Hint: fmt.Sprintf("GRANT SELECT, INSERT, DELETE ON `%s`.* TO '%s'@'127.0.0.1'; "+
+				"ignore this if they are granted through a role, a wildcard database or on columns",
+				d.Connection.Database, d.Connection.User),
+		})
+	}
+
+	return findings
+}
+
+// granted reports whether a SHOW GRANTS line gives privilege on the table.
+func granted(lines []string, privilege, database, table string) bool {
+	objects := []string{"*.*", database + ".*", database + "." + table}
+
+	for _, line := range lines {
+		match := grant.FindStringSubmatch(line)
+		if match == nil || !slices.Contains(objects, strings.ReplaceAll(match[2], "`", "")) {
+			continue
+		}
+
+		for item := range strings.SplitSeq(match[1], ",") {
+			item = strings.TrimSpace(item)
+			if item == "ALL PRIVILEGES" || item == privilege {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (d Doctor) tables(ctx context.Context) []Finding {
+	var findings []Finding
+
+	for _, table := range d.Tables {
+		findings = append(findings, d.table(ctx, table))
+	}
+
+	return findings
+}
+
+func (d Doctor) table(ctx context.Context, table string) Finding {
+	create := fmt.Sprintf("CREATE TABLE `%s`.`%s` (uuid varchar(50) NOT NULL) ENGINE=ARIA", d.Connection.Database, table)
+
+	var engine sql.NullString
+
+	err := d.DB.QueryRowContext(ctx,
+		"SELECT ENGINE FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+		d.Connection.Database, table,
+	).Scan(&engine)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Finding{Check: CheckTable, Status: StatusFail, Message: table + " does not exist", Hint: create}
+	}
+
+	if err != nil {
+		return Finding{Check: CheckTable, Status: StatusFail, Message: err.Error()}
+	}
+
+	var (
+		dataType string
+		length   sql.NullInt64
+	)
+
+	err = d.DB.QueryRowContext(ctx,
+		"SELECT DATA_TYPE, CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS "+
+			"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_NAME = 'uuid'",
+		d.Connection.Database, table,
+	).Scan(&dataType, &length)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Finding{
+			Check:   CheckTable,
+			Status:  StatusFail,
+			Message: table + " has no uuid column",
+			Hint:    fmt.Sprintf("ALTER TABLE `%s`.`%s` ADD COLUMN uuid varchar(50) NOT NULL", d.Connection.Database, table),
+		}
+	}
+
+	if err != nil {
+		return Finding{Check: CheckTable, Status: StatusFail, Message: err.Error()}
+	}
+
+	if !strings.Contains(dataType, "char") && !strings.Contains(dataType, "text") ||
+		length.Valid && length.Int64 < minUUIDLength {
+		return Finding{
+			Check:   CheckTable,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s.uuid is %s(%d), too small for a UUID", table, dataType, length.Int64),
+			Hint:    fmt.Sprintf("ALTER TABLE `%s`.`%s` MODIFY uuid varchar(50) NOT NULL", d.Connection.Database, table),
+		}
+	}
+
+	finding := Finding{
+		Check:   CheckTable,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("%s exists with engine %s", table, engine.String),
+	}
+
+	if strings.EqualFold(engine.String, "MEMORY") {
+		finding.Message += ", which does not verify that the volume is writable"
+	}
+
+	return finding
+}
+
+func (d Doctor) server(ctx context.Context) []Finding {
+	var (
+		version  string
+		readOnly bool
+	)
+
+	err := d.DB.QueryRowContext(ctx, "SELECT VERSION(), @@read_only").Scan(&version, &readOnly)
+	if err != nil {
+		return []Finding{{Check: CheckServer, Status: StatusFail, Message: err.Error()}}
+	}
+
+	if readOnly {
+		return []Finding{{
+			Check:   CheckServer,
+			Status:  StatusWarn,
+			Message: version + ", read_only is ON",
+			Hint:    "the write round-trip fails on a read-only server; probe replicas with ?mode=read",
+		}}
+	}
+
+	return []Finding{{Check: CheckServer, Status: StatusOK, Message: version + ", writable"}}
+}
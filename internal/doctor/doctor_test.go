@@ -0,0 +1,168 @@
+package doctor_test
+
+import (
+	"bytes"
+	"database/sql"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/doctor"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tableQuery  = "SELECT ENGINE FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?"
+	columnQuery = "SELECT DATA_TYPE, CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS " +
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_NAME = 'uuid'"
+)
+
+// newDoctor returns a Doctor for a local listener and a mock database.
+func newDoctor(t *testing.T) (doctor.Doctor, sqlmock.Sqlmock) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual), sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return doctor.Doctor{
+		Connection: mariadb.Connection{Host: host, Port: port, User: "healthcheck", Database: "healthcheck"},
+		DB:         db,
+		Tables:     []string{"status"},
+		Timeout:    time.Second,
+	}, mock
+}
+
+func statuses(findings []doctor.Finding) []doctor.Status {
+	var result []doctor.Status
+
+	for _, f := range findings {
+		result = append(result, f.Status)
+	}
+
+	return result
+}
+
+func TestDoctor(t *testing.T) {
+	t.Run("should pass a correct installation", func(t *testing.T) {
+		d, mock := newDoctor(t)
+
+		mock.ExpectPing()
+		mock.ExpectQuery("SHOW GRANTS").
+			WillReturnRows(sqlmock.NewRows([]string{"Grants"}).
+				AddRow("GRANT USAGE ON *.* TO `healthcheck`@`127.0.0.1`").
+				AddRow("GRANT ALL PRIVILEGES ON `healthcheck`.* TO `healthcheck`@`127.0.0.1`"))
+		mock.ExpectQuery(tableQuery).WithArgs("healthcheck", "status").
+			WillReturnRows(sqlmock.NewRows([]string{"ENGINE"}).AddRow("Aria"))
+		mock.ExpectQuery(columnQuery).WithArgs("healthcheck", "status").
+			WillReturnRows(sqlmock.NewRows([]string{"DATA_TYPE", "CHARACTER_MAXIMUM_LENGTH"}).AddRow("varchar", 50))
+		mock.ExpectQuery("SELECT VERSION(), @@read_only").
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@read_only"}).AddRow("11.4.2-MariaDB", 0))
+
+		findings := d.Run(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.False(t, doctor.Failed(findings))
+		assert.Equal(t, []doctor.Status{
+			doctor.StatusOK, doctor.StatusOK, doctor.StatusOK, doctor.StatusOK, doctor.StatusOK, doctor.StatusOK,
+		}, statuses(findings))
+
+		var out bytes.Buffer
+		require.NoError(t, doctor.Write(&out, findings))
+		assert.Contains(t, out.String(), "[OK  ] table: status exists with engine Aria\n")
+		assert.Contains(t, out.String(), "[OK  ] server: 11.4.2-MariaDB, writable\n")
+	})
+
+	t.Run("should explain a wrong password and skip the rest", func(t *testing.T) {
+		d, mock := newDoctor(t)
+
+		mock.ExpectPing().WillReturnError(&mysql.MySQLError{Number: 1045, Message: "Access denied"})
+
+		findings := d.Run(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.True(t, doctor.Failed(findings))
+		assert.Equal(t, []doctor.Status{
+			doctor.StatusOK, doctor.StatusOK, doctor.StatusFail, doctor.StatusSkip, doctor.StatusSkip, doctor.StatusSkip,
+		}, statuses(findings))
+		assert.Contains(t, findings[2].Hint, "DB_PASSWORD")
+	})
+
+	t.Run("should warn about missing grants with the statement to run", func(t *testing.T) {
+		d, mock := newDoctor(t)
+
+		mock.ExpectPing()
+		mock.ExpectQuery("SHOW GRANTS").
+			WillReturnRows(sqlmock.NewRows([]string{"Grants"}).
+				AddRow("GRANT SELECT, INSERT ON `healthcheck`.`status` TO `healthcheck`@`127.0.0.1`"))
+		mock.ExpectQuery(tableQuery).WithArgs("healthcheck", "status").
+			WillReturnRows(sqlmock.NewRows([]string{"ENGINE"}).AddRow("Aria"))
+		mock.ExpectQuery(columnQuery).WithArgs("healthcheck", "status").
+			WillReturnRows(sqlmock.NewRows([]string{"DATA_TYPE", "CHARACTER_MAXIMUM_LENGTH"}).AddRow("varchar", 50))
+		mock.ExpectQuery("SELECT VERSION(), @@read_only").
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@read_only"}).AddRow("11.4.2-MariaDB", 0))
+
+		findings := d.Run(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.False(t, doctor.Failed(findings))
+		assert.Equal(t, doctor.StatusWarn, findings[3].Status)
+		assert.Equal(t, "DELETE not found in SHOW GRANTS for status", findings[3].Message)
+		assert.Contains(t, findings[3].Hint, "GRANT SELECT, INSERT, DELETE ON `healthcheck`.*")
+	})
+
+	t.Run("should report a missing table", func(t *testing.T) {
+		d, mock := newDoctor(t)
+
+		mock.ExpectPing()
+		mock.ExpectQuery("SHOW GRANTS").
+			WillReturnRows(sqlmock.NewRows([]string{"Grants"}).
+				AddRow("GRANT ALL PRIVILEGES ON *.* TO `healthcheck`@`127.0.0.1`"))
+		mock.ExpectQuery(tableQuery).WithArgs("healthcheck", "status").WillReturnError(sql.ErrNoRows)
+
+		findings := d.Run(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, doctor.StatusFail, findings[4].Status)
+		assert.Contains(t, findings[4].Hint, "CREATE TABLE `healthcheck`.`status`")
+		assert.Equal(t, doctor.StatusSkip, findings[5].Status)
+	})
+
+	t.Run("should warn about a read-only server", func(t *testing.T) {
+		d, mock := newDoctor(t)
+		d.Tables = nil
+
+		mock.ExpectPing()
+		mock.ExpectQuery("SHOW GRANTS").WillReturnRows(sqlmock.NewRows([]string{"Grants"}))
+		mock.ExpectQuery("SELECT VERSION(), @@read_only").
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@read_only"}).AddRow("11.4.2-MariaDB", 1))
+
+		findings := d.Run(t.Context())
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.False(t, doctor.Failed(findings))
+		assert.Equal(t, doctor.StatusWarn, findings[len(findings)-1].Status)
+		assert.Contains(t, findings[len(findings)-1].Hint, "?mode=read")
+	})
+
+	t.Run("should fail when nothing listens on the port", func(t *testing.T) {
+		d, _ := newDoctor(t)
+		d.Connection.Port = "1"
+
+		findings := d.Run(t.Context())
+
+		assert.Equal(t, doctor.StatusFail, findings[1].Status)
+		assert.Contains(t, findings[1].Hint, "DB_PORT")
+	})
+}
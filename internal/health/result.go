@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Scope tells which probes a failing check counts against.
+type Scope int
+
+// Scopes are bit flags so a check can count against several probes.
+const (
+	ScopeLiveness Scope = 1 << iota
+	ScopeReadiness
+
+	ScopeNone Scope = 0
+	ScopeAll        = ScopeLiveness | ScopeReadiness
+)
+
+// Has reports whether s includes other.
+func (s Scope) Has(other Scope) bool {
+	return s&other != 0
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name     string         `json:"name"`
+	Status   Status         `json:"status"`
+	Scope    Scope          `json:"-"`
+	Message  string         `json:"message,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+	Duration time.Duration  `json:"duration"`
+}
+
+// DB is the part of *sql.DB the checks use, so callers can supply their own
+// pool or wrap it, e.g. for tracing.
+type DB interface {
+	PingContext(ctx context.Context) error
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Checker is an optional check run after the write round-trip succeeded.
+type Checker interface {
+	// Name identifies the check in responses, logs and configuration.
+	Name() string
+	// Check inspects the database. Failures are reported through the
+	// returned Result, never by panicking or blocking past ctx.
+	Check(ctx context.Context, db DB) Result
+}
+
+// StatusFor returns the status of r as seen by a probe of the given scope.
+// An unhealthy result that does not count against the probe is reported as
+// degraded, so it stays visible without failing the probe.
+func (r Result) StatusFor(scope Scope) Status {
+	if r.Status == StatusUnhealthy && !r.Scope.Has(scope) {
+		return StatusDegraded
+	}
+
+	return r.Status
+}
+
+// Report is the set of results produced for a single probe.
+type Report struct {
+	Results []Result
+}
+
+// Status returns the worst status of all results as seen by scope.
+func (r Report) Status(scope Scope) Status {
+	worst := StatusHealthy
+
+	for _, result := range r.Results {
+		worst = max(worst, result.StatusFor(scope))
+	}
+
+	return worst
+}
+
+// Worst returns the first result with the worst status as seen by scope.
+// The boolean is false when every result is healthy.
+func (r Report) Worst(scope Scope) (Result, bool) {
+	status := r.Status(scope)
+	if status == StatusHealthy {
+		return Result{}, false
+	}
+
+	for _, result := range r.Results {
+		if result.StatusFor(scope) == status {
+			return result, true
+		}
+	}
+
+	return Result{}, false
+}
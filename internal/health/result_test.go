@@ -0,0 +1,50 @@
+package health_test
+
+import (
+	"testing"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope(t *testing.T) {
+	t.Run("should combine scopes", func(t *testing.T) {
+		assert.True(t, health.ScopeAll.Has(health.ScopeLiveness))
+		assert.True(t, health.ScopeAll.Has(health.ScopeReadiness))
+		assert.False(t, health.ScopeReadiness.Has(health.ScopeLiveness))
+		assert.False(t, health.ScopeNone.Has(health.ScopeReadiness))
+	})
+}
+
+func TestReport(t *testing.T) {
+	report := health.Report{Results: []health.Result{
+		{Name: "roundtrip", Status: health.StatusHealthy, Scope: health.ScopeAll},
+		{Name: "slow", Status: health.StatusDegraded, Scope: health.ScopeAll},
+		{Name: "connections", Status: health.StatusUnhealthy, Scope: health.ScopeReadiness},
+	}}
+
+	t.Run("should fail readiness on a readiness-scoped check", func(t *testing.T) {
+		assert.Equal(t, health.StatusUnhealthy, report.Status(health.ScopeReadiness))
+
+		worst, ok := report.Worst(health.ScopeReadiness)
+		assert.True(t, ok)
+		assert.Equal(t, "connections", worst.Name)
+	})
+
+	t.Run("should only degrade liveness on a readiness-scoped check", func(t *testing.T) {
+		assert.Equal(t, health.StatusDegraded, report.Status(health.ScopeLiveness))
+
+		worst, ok := report.Worst(health.ScopeLiveness)
+		assert.True(t, ok)
+		assert.Equal(t, "slow", worst.Name)
+	})
+
+	t.Run("should report healthy when every check passed", func(t *testing.T) {
+		healthy := health.Report{Results: report.Results[:1]}
+
+		assert.Equal(t, health.StatusHealthy, healthy.Status(health.ScopeAll))
+
+		_, ok := healthy.Worst(health.ScopeAll)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,107 @@
+// Package health defines the aggregated health state of the database and
+// tracks transitions between states.
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Status is the aggregated health state. Higher values are worse, so the
+// overall state of several checks is their maximum.
+type Status int
+
+// Known health states.
+const (
+	StatusUnknown Status = iota
+	StatusHealthy
+	StatusDegraded
+	StatusUnhealthy
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusHealthy:
+		return "healthy"
+	case StatusDegraded:
+		return "degraded"
+	case StatusUnhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler so Status encodes as its name.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Worst returns the most severe of the given states.
+func Worst(states ...Status) Status {
+	worst := StatusUnknown
+
+	for _, s := range states {
+		worst = max(worst, s)
+	}
+
+	return worst
+}
+
+// Transition describes a change of the aggregated health state.
+type Transition struct {
+	From   Status
+	To     Status
+	Time   time.Time
+	Reason string
+}
+
+// Tracker remembers the last observed state. A Tracker is safe for
+// concurrent use; all methods on a nil *Tracker are no-ops.
+type Tracker struct {
+	mu      sync.Mutex
+	current Status
+}
+
+// NewTracker returns a tracker whose state is StatusUnknown.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Current returns the last observed state.
+func (t *Tracker) Current() Status {
+	if t == nil {
+		return StatusUnknown
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.current
+}
+
+// Observe records status and reports whether it differs from the previous
+// state. Starting up healthy is not considered a transition, so the first
+// observation only counts when the database is not healthy.
+func (t *Tracker) Observe(status Status, reason string) (Transition, bool) {
+	if t == nil {
+		return Transition{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.current
+	t.current = status
+
+	if prev == status || (prev == StatusUnknown && status == StatusHealthy) {
+		return Transition{}, false
+	}
+
+	return Transition{
+		From:   prev,
+		To:     status,
+		Time:   time.Now(),
+		Reason: reason,
+	}, true
+}
@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"testing"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	t.Run("should return the name of the state", func(t *testing.T) {
+		assert.Equal(t, "healthy", health.StatusHealthy.String())
+		assert.Equal(t, "degraded", health.StatusDegraded.String())
+		assert.Equal(t, "unhealthy", health.StatusUnhealthy.String())
+		assert.Equal(t, "unknown", health.StatusUnknown.String())
+	})
+
+	t.Run("should return the worst state", func(t *testing.T) {
+		assert.Equal(t, health.StatusDegraded, health.Worst(health.StatusHealthy, health.StatusDegraded))
+		assert.Equal(t, health.StatusUnknown, health.Worst())
+	})
+}
+
+func TestTracker(t *testing.T) {
+	t.Run("should not report starting up healthy", func(t *testing.T) {
+		tracker := health.NewTracker()
+
+		_, changed := tracker.Observe(health.StatusHealthy, "")
+
+		assert.False(t, changed)
+		assert.Equal(t, health.StatusHealthy, tracker.Current())
+	})
+
+	t.Run("should report starting up unhealthy", func(t *testing.T) {
+		tracker := health.NewTracker()
+
+		transition, changed := tracker.Observe(health.StatusUnhealthy, "boom")
+
+		assert.True(t, changed)
+		assert.Equal(t, health.StatusUnknown, transition.From)
+		assert.Equal(t, health.StatusUnhealthy, transition.To)
+		assert.Equal(t, "boom", transition.Reason)
+	})
+
+	t.Run("should report only changes", func(t *testing.T) {
+		tracker := health.NewTracker()
+		tracker.Observe(health.StatusHealthy, "")
+
+		_, changed := tracker.Observe(health.StatusHealthy, "")
+		assert.False(t, changed)
+
+		transition, changed := tracker.Observe(health.StatusDegraded, "slow")
+		assert.True(t, changed)
+		assert.Equal(t, health.StatusHealthy, transition.From)
+
+		_, changed = tracker.Observe(health.StatusDegraded, "slow")
+		assert.False(t, changed)
+	})
+
+	t.Run("should be a no-op on a nil tracker", func(t *testing.T) {
+		var tracker *health.Tracker
+
+		_, changed := tracker.Observe(health.StatusUnhealthy, "")
+
+		assert.False(t, changed)
+		assert.Equal(t, health.StatusUnknown, tracker.Current())
+	})
+}
@@ -0,0 +1,137 @@
+// Package history keeps a bounded, in-memory record of recent health-check
+// results so that the sequence of events around an incident can be
+// reconstructed without grepping logs.
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcome values stored in Entry.Outcome.
+const (
+	OutcomeOK          = "ok"
+	OutcomeDegraded    = "degraded"
+	OutcomeFailed      = "failed"
+	OutcomeMaintenance = "maintenance"
+	OutcomeDraining    = "draining"
+)
+
+// Stage is the duration of a single step of a check.
+type Stage struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Entry is a single recorded check.
+type Entry struct {
+	Time     time.Time     `json:"time"`
+	Probe    string        `json:"probe"`
+	Outcome  string        `json:"outcome"`
+	Duration time.Duration `json:"duration"`
+	Stages   []Stage       `json:"stages"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Ring is a fixed-size buffer of entries. Once full, every Add overwrites the
+// oldest entry. A Ring is safe for concurrent use.
+type Ring struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRing returns a ring holding at most size entries. A size of zero or
+// less returns nil; all methods on a nil *Ring are no-ops.
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		return nil
+	}
+
+	return &Ring{entries: make([]Entry, size)}
+}
+
+// Add records entry, evicting the oldest one when the ring is full.
+func (r *Ring) Add(entry Entry) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Entries returns a copy of the recorded entries, oldest first.
+func (r *Ring) Entries() []Entry {
+	if r == nil {
+		return []Entry{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]Entry{}, r.entries[:r.next]...)
+	}
+
+	out := make([]Entry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	out = append(out, r.entries[:r.next]...)
+
+	return out
+}
+
+// WriteCSV writes entries to w as CSV with a header row. Stage durations are
+// folded into a single "name=ms;name=ms" column so the column set stays fixed.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"time", "probe", "outcome", "duration_ms", "stages", "error"})
+	if err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		stages := make([]string, 0, len(entry.Stages))
+		for _, stage := range entry.Stages {
+			stages = append(stages, stage.Name+"="+milliseconds(stage.Duration))
+		}
+
+		err := writer.Write([]string{
+			entry.Time.UTC().Format(time.RFC3339Nano),
+			entry.Probe,
+			entry.Outcome,
+			milliseconds(entry.Duration),
+			strings.Join(stages, ";"),
+			entry.Error,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
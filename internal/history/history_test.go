@@ -0,0 +1,72 @@
+package history_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	t.Run("should return nil for non-positive size", func(t *testing.T) {
+		ring := history.NewRing(0)
+
+		assert.Nil(t, ring)
+
+		ring.Add(history.Entry{Probe: "liveness"})
+		assert.Empty(t, ring.Entries())
+	})
+
+	t.Run("should return entries oldest first before wrapping", func(t *testing.T) {
+		ring := history.NewRing(3)
+		ring.Add(history.Entry{Probe: "a"})
+		ring.Add(history.Entry{Probe: "b"})
+
+		entries := ring.Entries()
+
+		require.Len(t, entries, 2)
+		assert.Equal(t, "a", entries[0].Probe)
+		assert.Equal(t, "b", entries[1].Probe)
+	})
+
+	t.Run("should evict the oldest entry once full", func(t *testing.T) {
+		ring := history.NewRing(2)
+		ring.Add(history.Entry{Probe: "a"})
+		ring.Add(history.Entry{Probe: "b"})
+		ring.Add(history.Entry{Probe: "c"})
+
+		entries := ring.Entries()
+
+		require.Len(t, entries, 2)
+		assert.Equal(t, "b", entries[0].Probe)
+		assert.Equal(t, "c", entries[1].Probe)
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Run("should write header and rows", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := history.WriteCSV(&buf, []history.Entry{{
+			Time:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Probe:    "readiness",
+			Outcome:  history.OutcomeFailed,
+			Duration: 3 * time.Millisecond,
+			Stages: []history.Stage{
+				{Name: "insert", Duration: time.Millisecond},
+				{Name: "select", Duration: 2 * time.Millisecond},
+			},
+			Error: "failed to select row: boom",
+		}})
+
+		require.NoError(t, err)
+		assert.Equal(t,
+			"time,probe,outcome,duration_ms,stages,error\n"+
+				"2026-01-02T03:04:05Z,readiness,failed,3.000,insert=1.000;select=2.000,failed to select row: boom\n",
+			buf.String(),
+		)
+	})
+}
@@ -0,0 +1,254 @@
+// Package kube is a minimal Kubernetes API client acting on the pod the
+// sidecar runs in. It authenticates with the pod's service account and only
+// implements the few calls the sidecar needs, so client-go is not pulled in.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Paths of the service account files mounted into every pod.
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+	namespaceFile     = serviceAccountDir + "/namespace"
+)
+
+// maxErrorBody bounds how much of an error response is quoted in errors.
+const maxErrorBody = 512
+
+var errStatus = errors.New("unexpected status code")
+
+// Config configures a Client.
+type Config struct {
+	// Host is the API server URL, e.g. https://10.0.0.1:443.
+	Host string
+	// TokenFile holds the bearer token. It is re-read on every request
+	// because projected service account tokens are rotated. Empty sends no
+	// token.
+	TokenFile string
+	// CAFile holds the CA bundle of the API server. Empty uses the system
+	// roots.
+	CAFile string
+	// Namespace and Pod identify the pod the sidecar runs in.
+	Namespace string
+	Pod       string
+	// PodUID is the UID of the pod, e.g. from the downward API. Empty looks
+	// it up on first use.
+	PodUID string
+	// Timeout bounds a single request.
+	Timeout time.Duration
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("no API server host configured")
+	}
+
+	if c.Namespace == "" {
+		return fmt.Errorf("no namespace configured")
+	}
+
+	if c.Pod == "" {
+		return fmt.Errorf("no pod name configured")
+	}
+
+	return nil
+}
+
+// InClusterConfig returns the configuration for the pod the process runs in.
+// namespace and pod override what is discovered; the pod name defaults to the
+// hostname, which Kubernetes sets to the pod name.
+func InClusterConfig(namespace, pod string, timeout time.Duration) (Config, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return Config{}, fmt.Errorf("not running in a cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+
+	if namespace == "" {
+		data, err := os.ReadFile(namespaceFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read namespace: %w", err)
+		}
+
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	if pod == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read pod name: %w", err)
+		}
+
+		pod = hostname
+	}
+
+	return Config{
+		Host:      "https://" + net.JoinHostPort(host, port),
+		TokenFile: tokenFile,
+		CAFile:    caFile,
+		Namespace: namespace,
+		Pod:       pod,
+		Timeout:   timeout,
+	}, nil
+}
+
+// Client talks to the Kubernetes API on behalf of one pod. A Client is safe
+// for concurrent use.
+type Client struct {
+	cfg    Config
+	client *http.Client
+
+	mu  sync.Mutex
+	uid string
+}
+
+// New returns a client for cfg.
+func New(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate kubernetes config: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA: %w", err)
+		}
+
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Client{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		uid:    cfg.PodUID,
+	}, nil
+}
+
+// Pod returns the name of the pod the client acts on.
+func (c *Client) Pod() string {
+	return c.cfg.Pod
+}
+
+// Namespace returns the namespace of the pod the client acts on.
+func (c *Client) Namespace() string {
+	return c.cfg.Namespace
+}
+
+// PatchPodLabels sets labels on the pod with a JSON merge patch. Other labels
+// are left alone. Requires the "patch" verb on pods.
+func (c *Client) PatchPodLabels(ctx context.Context, labels map[string]string) error {
+	body, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"labels": labels},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	if err := c.do(ctx, http.MethodPatch, c.podPath(), "application/merge-patch+json", body, nil); err != nil {
+		return fmt.Errorf("failed to patch labels of pod %s/%s: %w", c.cfg.Namespace, c.cfg.Pod, err)
+	}
+
+	return nil
+}
+
+// podUID returns the UID of the pod, reading the pod once when it was not
+// configured. Requires the "get" verb on pods in that case.
+func (c *Client) podUID(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.uid != "" {
+		return c.uid, nil
+	}
+
+	var pod struct {
+		Metadata struct {
+			UID string `json:"uid"`
+		} `json:"metadata"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, c.podPath(), "", nil, &pod); err != nil {
+		return "", fmt.Errorf("failed to get pod %s/%s: %w", c.cfg.Namespace, c.cfg.Pod, err)
+	}
+
+	c.uid = pod.Metadata.UID
+
+	return c.uid, nil
+}
+
+func (c *Client) namespacePath() string {
+	return "/api/v1/namespaces/" + url.PathEscape(c.cfg.Namespace)
+}
+
+func (c *Client) podPath() string {
+	return c.namespacePath() + "/pods/" + url.PathEscape(c.cfg.Pod)
+}
+
+// do sends body and decodes the response into out unless out is nil.
+func (c *Client) do(ctx context.Context, method, path, contentType string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.Host, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if c.cfg.TokenFile != "" {
+		token, err := os.ReadFile(c.cfg.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+		return fmt.Errorf("%w: %d: %s", errStatus, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
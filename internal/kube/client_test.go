@@ -0,0 +1,155 @@
+package kube_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	method        string
+	path          string
+	contentType   string
+	authorization string
+	body          map[string]any
+}
+
+// fakeAPIServer is a local stand-in for the Kubernetes API server. It records
+// every request and answers with status; a successful GET returns a pod.
+type fakeAPIServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []request
+	status   int
+}
+
+func newFakeAPIServer(t *testing.T, status int) *fakeAPIServer {
+	t.Helper()
+
+	fake := &fakeAPIServer{status: status}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		var body map[string]any
+		if len(data) > 0 {
+			assert.NoError(t, json.Unmarshal(data, &body))
+		}
+
+		fake.mu.Lock()
+		fake.requests = append(fake.requests, request{
+			method:        r.Method,
+			path:          r.URL.Path,
+			contentType:   r.Header.Get("Content-Type"),
+			authorization: r.Header.Get("Authorization"),
+			body:          body,
+		})
+		fake.mu.Unlock()
+
+		w.WriteHeader(fake.status)
+
+		if fake.status >= http.StatusBadRequest {
+			_, _ = w.Write([]byte(`{"kind":"Status","message":"pods \"mariadb-0\" is forbidden"}`))
+		} else if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"kind":"Pod","metadata":{"name":"mariadb-0","uid":"pod-uid"}}`))
+		}
+	}))
+	t.Cleanup(fake.Close)
+
+	return fake
+}
+
+func newClient(t *testing.T, host string) *kube.Client {
+	t.Helper()
+
+	token := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(token, []byte("secret\n"), 0o600))
+
+	client, err := kube.New(kube.Config{
+		Host:      host,
+		TokenFile: token,
+		Namespace: "db",
+		Pod:       "mariadb-0",
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestPatchPodLabels(t *testing.T) {
+	t.Run("should send a merge patch with the labels", func(t *testing.T) {
+		fake := newFakeAPIServer(t, http.StatusOK)
+
+		err := newClient(t, fake.URL).PatchPodLabels(t.Context(), map[string]string{"mariadb-role": "primary"})
+
+		require.NoError(t, err)
+		require.Len(t, fake.requests, 1)
+		assert.Equal(t, request{
+			method:        http.MethodPatch,
+			path:          "/api/v1/namespaces/db/pods/mariadb-0",
+			contentType:   "application/merge-patch+json",
+			authorization: "Bearer secret",
+			body: map[string]any{
+				"metadata": map[string]any{"labels": map[string]any{"mariadb-role": "primary"}},
+			},
+		}, fake.requests[0])
+	})
+
+	t.Run("should return the API error", func(t *testing.T) {
+		fake := newFakeAPIServer(t, http.StatusForbidden)
+
+		err := newClient(t, fake.URL).PatchPodLabels(t.Context(), map[string]string{"mariadb-role": "primary"})
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "403")
+		assert.ErrorContains(t, err, "is forbidden")
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should return error for missing pod", func(t *testing.T) {
+		_, err := kube.New(kube.Config{Host: "https://127.0.0.1", Namespace: "db"})
+
+		assert.ErrorContains(t, err, "no pod name")
+	})
+
+	t.Run("should return error for a CA file without certificates", func(t *testing.T) {
+		ca := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(ca, []byte("not a certificate"), 0o600))
+
+		_, err := kube.New(kube.Config{Host: "https://127.0.0.1", Namespace: "db", Pod: "mariadb-0", CAFile: ca})
+
+		assert.ErrorContains(t, err, "no certificates")
+	})
+}
+
+func TestInClusterConfig(t *testing.T) {
+	t.Run("should return error outside a cluster", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+		_, err := kube.InClusterConfig("db", "mariadb-0", 0)
+
+		assert.ErrorContains(t, err, "not running in a cluster")
+	})
+
+	t.Run("should build the API server URL", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "fd00::1")
+		t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+		cfg, err := kube.InClusterConfig("db", "mariadb-0", 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://[fd00::1]:443", cfg.Host)
+		assert.Equal(t, "mariadb-0", cfg.Pod)
+	})
+}
@@ -0,0 +1,83 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event types, as shown by kubectl describe.
+const (
+	EventNormal  = "Normal"
+	EventWarning = "Warning"
+)
+
+// component identifies the sidecar as the source of its Events.
+const component = "mariadb-healthcheck"
+
+// maxEventMessage is the longest message the API server accepts on an Event.
+const maxEventMessage = 1024
+
+// Event is a Kubernetes Event about the pod.
+type Event struct {
+	// Type is EventNormal or EventWarning.
+	Type string
+	// Reason is a short UpperCamelCase cause, e.g. InsertFailed.
+	Reason  string
+	Message string
+	Time    time.Time
+}
+
+// CreateEvent creates event on the pod, so it shows up in kubectl describe
+// pod. Requires the "create" verb on events, and "get" on pods unless the pod
+// UID was configured.
+func (c *Client) CreateEvent(ctx context.Context, event Event) error {
+	uid, err := c.podUID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+
+	message := event.Message
+	if len(message) > maxEventMessage {
+		message = strings.ToValidUTF8(message[:maxEventMessage], "")
+	}
+
+	timestamp := event.Time.UTC().Format(time.RFC3339)
+
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]any{
+			"generateName": c.cfg.Pod + ".",
+			"namespace":    c.cfg.Namespace,
+		},
+		"involvedObject": map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"name":       c.cfg.Pod,
+			"namespace":  c.cfg.Namespace,
+			"uid":        uid,
+		},
+		"type":               event.Type,
+		"reason":             event.Reason,
+		"message":            message,
+		"firstTimestamp":     timestamp,
+		"lastTimestamp":      timestamp,
+		"count":              1,
+		"source":             map[string]any{"component": component},
+		"reportingComponent": component,
+		"reportingInstance":  c.cfg.Pod,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := c.do(ctx, http.MethodPost, c.namespacePath()+"/events", "application/json", body, nil); err != nil {
+		return fmt.Errorf("failed to create event %s on pod %s/%s: %w", event.Reason, c.cfg.Namespace, c.cfg.Pod, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package kube_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEvent(t *testing.T) {
+	event := kube.Event{
+		Type:    kube.EventWarning,
+		Reason:  "InsertFailed",
+		Message: "unhealthy: roundtrip: failed to insert row",
+		Time:    time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should create an event on the pod", func(t *testing.T) {
+		fake := newFakeAPIServer(t, http.StatusCreated)
+		client := newClient(t, fake.URL)
+
+		require.NoError(t, client.CreateEvent(t.Context(), event))
+		require.NoError(t, client.CreateEvent(t.Context(), event))
+
+		// The pod UID is looked up once and cached.
+		require.Len(t, fake.requests, 3)
+		assert.Equal(t, http.MethodGet, fake.requests[0].method)
+		assert.Equal(t, "/api/v1/namespaces/db/pods/mariadb-0", fake.requests[0].path)
+
+		created := fake.requests[1]
+		assert.Equal(t, http.MethodPost, created.method)
+		assert.Equal(t, "/api/v1/namespaces/db/events", created.path)
+		assert.Equal(t, "Warning", created.body["type"])
+		assert.Equal(t, "InsertFailed", created.body["reason"])
+		assert.Equal(t, "unhealthy: roundtrip: failed to insert row", created.body["message"])
+		assert.Equal(t, "2026-05-01T10:00:00Z", created.body["lastTimestamp"])
+		assert.Equal(t, map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"name":       "mariadb-0",
+			"namespace":  "db",
+			"uid":        "pod-uid",
+		}, created.body["involvedObject"])
+	})
+
+	t.Run("should truncate long messages", func(t *testing.T) {
+		fake := newFakeAPIServer(t, http.StatusCreated)
+
+		long := event
+		long.Message = strings.Repeat("x", 2000)
+
+		require.NoError(t, newClient(t, fake.URL).CreateEvent(t.Context(), long))
+
+		assert.Len(t, fake.requests[1].body["message"], 1024)
+	})
+
+	t.Run("should return error when the pod cannot be read", func(t *testing.T) {
+		fake := newFakeAPIServer(t, http.StatusForbidden)
+
+		err := newClient(t, fake.URL).CreateEvent(t.Context(), event)
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to get pod db/mariadb-0")
+		assert.Len(t, fake.requests, 1)
+	})
+}
@@ -0,0 +1,98 @@
+// Package maintenance holds the maintenance switch of the sidecar. While it
+// is on, probes stop exercising the database so planned work (ALTER TABLE,
+// upgrades, backups) does not get MariaDB restarted.
+package maintenance
+
+import (
+	"sync"
+	"time"
+)
+
+// State is a snapshot of the maintenance switch.
+type State struct {
+	Active bool      `json:"active"`
+	Since  time.Time `json:"since,omitzero"`
+	// Until is the moment maintenance ends on its own; zero means never.
+	Until  time.Time `json:"until,omitzero"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Mode is the maintenance switch. A Mode is safe for concurrent use.
+type Mode struct {
+	mu    sync.Mutex
+	state State
+	now   func() time.Time
+}
+
+// New returns a switch that is off.
+func New() *Mode {
+	return &Mode{now: time.Now}
+}
+
+// Enable turns maintenance on. A positive ttl makes it expire automatically,
+// so a forgotten maintenance window cannot silence probes forever.
+func (m *Mode) Enable(ttl time.Duration, reason string) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.enable(ttl, reason)
+}
+
+// enable turns maintenance on; m.mu must be held.
+func (m *Mode) enable(ttl time.Duration, reason string) State {
+	now := m.now()
+	m.state = State{Active: true, Since: now, Reason: reason}
+
+	if ttl > 0 {
+		m.state.Until = now.Add(ttl)
+	}
+
+	return m.state
+}
+
+// Disable turns maintenance off.
+func (m *Mode) Disable() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state = State{}
+
+	return m.state
+}
+
+// Toggle flips the switch, enabling it with ttl when it was off. The state is
+// read and flipped under one lock, so concurrent toggles alternate.
+func (m *Mode) Toggle(ttl time.Duration, reason string) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.current().Active {
+		m.state = State{}
+
+		return m.state
+	}
+
+	return m.enable(ttl, reason)
+}
+
+// Current returns the current state, turning maintenance off first when it
+// has expired.
+func (m *Mode) Current() State {
+	if m == nil {
+		return State{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.current()
+}
+
+// current returns the current state, expiring it first; m.mu must be held.
+func (m *Mode) current() State {
+	if m.state.Active && !m.state.Until.IsZero() && !m.now().Before(m.state.Until) {
+		m.state = State{}
+	}
+
+	return m.state
+}
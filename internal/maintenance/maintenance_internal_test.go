@@ -0,0 +1,72 @@
+package maintenance
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMode(t *testing.T) {
+	t.Run("should start disabled", func(t *testing.T) {
+		assert.False(t, New().Current().Active)
+	})
+
+	t.Run("should enable without expiry", func(t *testing.T) {
+		mode := New()
+
+		state := mode.Enable(0, "upgrade")
+
+		assert.True(t, state.Active)
+		assert.True(t, state.Until.IsZero())
+		assert.Equal(t, "upgrade", mode.Current().Reason)
+	})
+
+	t.Run("should expire after ttl", func(t *testing.T) {
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		mode := &Mode{now: func() time.Time { return now }}
+
+		mode.Enable(time.Minute, "backup")
+		assert.True(t, mode.Current().Active)
+
+		now = now.Add(time.Minute)
+		assert.False(t, mode.Current().Active)
+	})
+
+	t.Run("should toggle", func(t *testing.T) {
+		mode := New()
+
+		assert.True(t, mode.Toggle(0, "signal").Active)
+		assert.False(t, mode.Toggle(0, "signal").Active)
+	})
+
+	t.Run("should alternate concurrent toggles", func(t *testing.T) {
+		mode := New()
+
+		var (
+			wg      sync.WaitGroup
+			enabled atomic.Int32
+		)
+
+		for range 100 {
+			wg.Go(func() {
+				if mode.Toggle(0, "signal").Active {
+					enabled.Add(1)
+				}
+			})
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, int32(50), enabled.Load())
+		assert.False(t, mode.Current().Active)
+	})
+
+	t.Run("should report disabled on a nil switch", func(t *testing.T) {
+		var mode *Mode
+
+		assert.False(t, mode.Current().Active)
+	})
+}
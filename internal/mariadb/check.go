@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
 )
 
 // Sentinel errors for the health-check stages. Consumers should match on
@@ -16,55 +19,168 @@ var (
 	ErrScan     = errors.New("failed to scan row")
 	ErrValidate = errors.New("failed to validate row")
 	ErrDelete   = errors.New("failed to delete row")
+	ErrPing     = errors.New("failed to ping server")
+	ErrRead     = errors.New("failed to read status table")
+)
+
+// Mode selects how deep a round-trip goes.
+type Mode string
+
+// Round-trip modes, from the cheapest to the most thorough.
+const (
+	// ModePing only sends COM_PING.
+	ModePing Mode = "ping"
+	// ModeRead runs a SELECT against the status table.
+	ModeRead Mode = "read"
+	// ModeWrite runs the INSERT -> SELECT -> DELETE sequence.
+	ModeWrite Mode = "write"
 )
 
+// Names of the round-trip stages reported in Stage.Name.
+const (
+	StageInsert = "insert"
+	StageSelect = "select"
+	StageDelete = "delete"
+	StagePing   = "ping"
+	StageRead   = "read"
+)
+
+// Stage records how long a single step of the round-trip took.
+type Stage struct {
+	Name     string
+	Duration time.Duration
+}
+
 // RunCheck executes the INSERT -> SELECT -> (optional) DELETE health-check
-// sequence using uuid as the UUID-shaped value written to the status table.
-// On failure it returns one of the sentinel errors above wrapped with the
-// underlying cause. Stage errors are NOT logged here — the HTTP handler is
-// the single error-logging boundary so callers can adjust verbosity in one
-// place.
-func RunCheck(ctx context.Context, db *sql.DB, uuid string, deleteRow bool) error {
-	if err := InsertRow(ctx, db, uuid); err != nil {
-		return fmt.Errorf("%w: %v", ErrInsert, err)
+// sequence against table using uuid as the UUID-shaped value written to it.
+// A positive stageTimeout bounds every stage on its own, so a stuck INSERT
+// cannot use up the time left for the others; zero only keeps the deadline of
+// ctx. It returns the timing of every stage that was attempted, including the
+// one that failed. On failure the error wraps both one of the sentinel errors
+// above and the underlying driver error, so Classify can inspect the latter.
+// Stage errors are NOT logged here — the HTTP handler is the single
+// error-logging boundary so callers can adjust verbosity in one place.
+func RunCheck(ctx context.Context, db health.DB, table, uuid string, deleteRow bool, stageTimeout time.Duration) ([]Stage, error) {
+	var stages []Stage
+
+	stageCtx, cancel := stageContext(ctx, stageTimeout)
+	start := time.Now()
+	err := InsertRow(stageCtx, db, table, uuid)
+	stages = append(stages, Stage{Name: StageInsert, Duration: time.Since(start)})
+
+	cancel()
+
+	if err != nil {
+		return stages, fmt.Errorf("%w: %w", ErrInsert, err)
 	}
 
 	slog.Debug(
 		"Executed query to insert row",
+		"table", table,
 		"UUID", uuid,
 	)
 
-	row, err := SelectRow(ctx, db, uuid)
+	stages, err = selectStage(ctx, db, table, uuid, stageTimeout, stages)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrSelect, err)
+		return stages, err
+	}
+
+	if deleteRow {
+		stageCtx, cancel := stageContext(ctx, stageTimeout)
+		start = time.Now()
+		err := DeleteRow(stageCtx, db, table, uuid)
+		stages = append(stages, Stage{Name: StageDelete, Duration: time.Since(start)})
+
+		cancel()
+
+		if err != nil {
+			return stages, fmt.Errorf("%w: %w", ErrDelete, err)
+		}
+
+		slog.Debug(
+			"Executed query to delete row",
+			"table", table,
+			"UUID", uuid,
+		)
+	}
+
+	return stages, nil
+}
+
+// selectStage reads back the inserted row. The stage context stays alive
+// until the row is scanned, as canceling it closes the row.
+func selectStage(
+	ctx context.Context, db health.DB, table, uuid string, stageTimeout time.Duration, stages []Stage,
+) ([]Stage, error) {
+	ctx, cancel := stageContext(ctx, stageTimeout)
+	defer cancel()
+
+	start := time.Now()
+	row, err := SelectRow(ctx, db, table, uuid)
+
+	if err != nil {
+		stages = append(stages, Stage{Name: StageSelect, Duration: time.Since(start)})
+		return stages, fmt.Errorf("%w: %w", ErrSelect, err)
 	}
 
 	slog.Debug(
 		"Executed query to select row",
+		"table", table,
 		"UUID", uuid,
 	)
 
 	var value string
-	if err := row.Scan(&value); err != nil {
+	err = row.Scan(&value)
+	stages = append(stages, Stage{Name: StageSelect, Duration: time.Since(start)})
+
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("%w: inserted row not found", ErrValidate)
+			return stages, fmt.Errorf("%w: inserted row not found", ErrValidate)
 		}
 
-		return fmt.Errorf("%w: %v", ErrScan, err)
+		return stages, fmt.Errorf("%w: %w", ErrScan, err)
 	}
 
-	_ = value
+	return stages, nil
+}
 
-	if deleteRow {
-		if err := DeleteRow(ctx, db, uuid); err != nil {
-			return fmt.Errorf("%w: %v", ErrDelete, err)
-		}
+// stageContext bounds a single stage by timeout; a non-positive timeout only
+// keeps the deadline of ctx.
+func stageContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
 
-		slog.Debug(
-			"Executed query to delete row",
-			"UUID", uuid,
-		)
+	return context.WithTimeout(ctx, timeout)
+}
+
+// RunPing checks that the server answers COM_PING.
+func RunPing(ctx context.Context, db health.DB) ([]Stage, error) {
+	start := time.Now()
+	err := db.PingContext(ctx)
+	stages := []Stage{{Name: StagePing, Duration: time.Since(start)}}
+
+	if err != nil {
+		return stages, fmt.Errorf("%w: %w", ErrPing, err)
+	}
+
+	return stages, nil
+}
+
+// RunRead checks that table can be read without writing to it. An empty
+// table is fine. A positive stageTimeout bounds the read like a RunCheck
+// stage.
+func RunRead(ctx context.Context, db health.DB, table string, stageTimeout time.Duration) ([]Stage, error) {
+	ctx, cancel := stageContext(ctx, stageTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ReadRow(ctx, db, table)
+	stages := []Stage{{Name: StageRead, Duration: time.Since(start)}}
+
+	if err != nil {
+		return stages, fmt.Errorf("%w: %w", ErrRead, err)
 	}
 
-	return nil
+	return stages, nil
 }
@@ -3,6 +3,7 @@ package mariadb_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
@@ -28,10 +29,14 @@ func TestRunCheck(t *testing.T) {
 			WithArgs(uuid).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, true)
+		stages, err := mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 0)
 
 		assert.NoError(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
+		require.Len(t, stages, 3)
+		assert.Equal(t, mariadb.StageInsert, stages[0].Name)
+		assert.Equal(t, mariadb.StageSelect, stages[1].Name)
+		assert.Equal(t, mariadb.StageDelete, stages[2].Name)
 	})
 
 	t.Run("should succeed when delete is disabled", func(t *testing.T) {
@@ -46,10 +51,11 @@ func TestRunCheck(t *testing.T) {
 			WithArgs(uuid).
 			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow(uuid))
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, false)
+		stages, err := mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, false, 0)
 
 		assert.NoError(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Len(t, stages, 2)
 	})
 
 	t.Run("should return ErrInsert on insert failure", func(t *testing.T) {
@@ -61,11 +67,13 @@ func TestRunCheck(t *testing.T) {
 			WithArgs(uuid).
 			WillReturnError(errors.New("insert failed"))
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, true)
+		stages, err := mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 0)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, mariadb.ErrInsert)
 		require.NoError(t, mock.ExpectationsWereMet())
+		require.Len(t, stages, 1)
+		assert.Equal(t, mariadb.StageInsert, stages[0].Name)
 	})
 
 	t.Run("should return ErrSelect on select failure", func(t *testing.T) {
@@ -80,7 +88,7 @@ func TestRunCheck(t *testing.T) {
 			WithArgs(uuid).
 			WillReturnError(errors.New("select failed"))
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, true)
+		_, err = mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 0)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, mariadb.ErrSelect)
@@ -103,7 +111,7 @@ func TestRunCheck(t *testing.T) {
 					RowError(0, errors.New("scan boom")),
 			)
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, true)
+		_, err = mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 0)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, mariadb.ErrScan)
@@ -122,7 +130,7 @@ func TestRunCheck(t *testing.T) {
 			WithArgs(uuid).
 			WillReturnRows(sqlmock.NewRows([]string{"uuid"}))
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, true)
+		_, err = mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 0)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, mariadb.ErrValidate)
@@ -144,10 +152,106 @@ func TestRunCheck(t *testing.T) {
 			WithArgs(uuid).
 			WillReturnError(errors.New("delete failed"))
 
-		err = mariadb.RunCheck(t.Context(), db, uuid, true)
+		_, err = mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 0)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, mariadb.ErrDelete)
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("should bound every stage by the stage timeout", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(uuid).
+			WillDelayFor(time.Second).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		stages, err := mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, true, 20*time.Millisecond)
+
+		require.ErrorIs(t, err, mariadb.ErrInsert)
+		require.Len(t, stages, 1)
+		assert.Less(t, stages[0].Duration, time.Second)
+	})
+
+	t.Run("should scan the selected row within the stage timeout", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(uuid).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT uuid FROM status WHERE uuid = ?").
+			WithArgs(uuid).
+			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow(uuid))
+
+		stages, err := mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, uuid, false, time.Second)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Len(t, stages, 2)
+	})
+}
+
+func TestRunPing(t *testing.T) {
+	t.Run("should succeed when the server answers", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectPing()
+
+		stages, err := mariadb.RunPing(t.Context(), db)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		require.Len(t, stages, 1)
+		assert.Equal(t, mariadb.StagePing, stages[0].Name)
+	})
+
+	t.Run("should return ErrPing when the server does not answer", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectPing().WillReturnError(errors.New("gone"))
+
+		_, err = mariadb.RunPing(t.Context(), db)
+
+		require.ErrorIs(t, err, mariadb.ErrPing)
+	})
+}
+
+func TestRunRead(t *testing.T) {
+	t.Run("should succeed on an empty table", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT uuid FROM status LIMIT 1").
+			WillReturnRows(sqlmock.NewRows([]string{"uuid"}))
+
+		stages, err := mariadb.RunRead(t.Context(), db, mariadb.DefaultTable, 0)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		require.Len(t, stages, 1)
+		assert.Equal(t, mariadb.StageRead, stages[0].Name)
+	})
+
+	t.Run("should return ErrRead when the select fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT uuid FROM status LIMIT 1").
+			WillReturnError(errors.New("no such table"))
+
+		_, err = mariadb.RunRead(t.Context(), db, mariadb.DefaultTable, 0)
+
+		require.ErrorIs(t, err, mariadb.ErrRead)
+	})
 }
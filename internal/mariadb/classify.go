@@ -0,0 +1,134 @@
+package mariadb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrorCategory groups driver errors by what they mean for the operator.
+type ErrorCategory string
+
+// Error categories returned by Classify.
+const (
+	CategoryAuthentication     ErrorCategory = "authentication"
+	CategoryUnknownDatabase    ErrorCategory = "unknown_database"
+	CategoryPrivileges         ErrorCategory = "privileges"
+	CategoryTooManyConnections ErrorCategory = "too_many_connections"
+	CategoryReadOnly           ErrorCategory = "read_only"
+	CategoryLock               ErrorCategory = "lock"
+	CategoryDiskFull           ErrorCategory = "disk_full"
+	CategoryUnknownTable       ErrorCategory = "unknown_table"
+	CategoryNetwork            ErrorCategory = "network"
+	CategoryUnknown            ErrorCategory = "unknown"
+)
+
+// Categories lists every category, in a stable order.
+var Categories = []ErrorCategory{
+	CategoryAuthentication,
+	CategoryUnknownDatabase,
+	CategoryPrivileges,
+	CategoryTooManyConnections,
+	CategoryReadOnly,
+	CategoryLock,
+	CategoryDiskFull,
+	CategoryUnknownTable,
+	CategoryNetwork,
+	CategoryUnknown,
+}
+
+// MariaDB server error numbers, see
+// https://mariadb.com/kb/en/mariadb-error-code-reference/.
+const (
+	erDiskFull           = 1021
+	erGetErrno           = 1030
+	erTooManyConnections = 1040
+	erDBAccessDenied     = 1044
+	erAccessDenied       = 1045
+	erBadDB              = 1049
+	erRecordFileFull     = 1114
+	erTableAccessDenied  = 1142
+	erColumnAccessDenied = 1143
+	erNoSuchTable        = 1146
+	erLockWaitTimeout    = 1205
+	erLockDeadlock       = 1213
+	erSpecificAccess     = 1227
+	erOptionPreventsStmt = 1290
+)
+
+// enospc matches "Got error 28 from storage engine": the engine ran into
+// ENOSPC while writing.
+var enospc = regexp.MustCompile(`\berror 28\b`)
+
+// IsMisconfiguration reports whether errors of this category are the fault of
+// the sidecar's own configuration (credentials, grants, database or status
+// table) rather than of the MariaDB server.
+func (c ErrorCategory) IsMisconfiguration() bool {
+	switch c {
+	case CategoryAuthentication, CategoryUnknownDatabase, CategoryPrivileges, CategoryUnknownTable:
+		return true
+	default:
+		return false
+	}
+}
+
+// Classify maps err to an ErrorCategory using the MariaDB error number when
+// the driver returned one, and the error type otherwise.
+func Classify(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return classifyNumber(mysqlErr)
+	}
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, mysql.ErrInvalidConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &netErr):
+		return CategoryNetwork
+	default:
+		return CategoryUnknown
+	}
+}
+
+func classifyNumber(err *mysql.MySQLError) ErrorCategory {
+	switch err.Number {
+	case erAccessDenied:
+		return CategoryAuthentication
+	case erBadDB:
+		return CategoryUnknownDatabase
+	case erDBAccessDenied, erTableAccessDenied, erColumnAccessDenied, erSpecificAccess:
+		return CategoryPrivileges
+	case erTooManyConnections:
+		return CategoryTooManyConnections
+	case erOptionPreventsStmt:
+		return CategoryReadOnly
+	case erLockWaitTimeout, erLockDeadlock:
+		return CategoryLock
+	case erDiskFull, erRecordFileFull:
+		return CategoryDiskFull
+	case erNoSuchTable:
+		return CategoryUnknownTable
+	case erGetErrno:
+		if enospc.MatchString(err.Message) {
+			return CategoryDiskFull
+		}
+
+		return CategoryUnknown
+	default:
+		return CategoryUnknown
+	}
+}
@@ -0,0 +1,89 @@
+package mariadb_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		category mariadb.ErrorCategory
+	}{
+		{name: "access denied", err: &mysql.MySQLError{Number: 1045}, category: mariadb.CategoryAuthentication},
+		{name: "unknown database", err: &mysql.MySQLError{Number: 1049}, category: mariadb.CategoryUnknownDatabase},
+		{name: "database access denied", err: &mysql.MySQLError{Number: 1044}, category: mariadb.CategoryPrivileges},
+		{name: "table access denied", err: &mysql.MySQLError{Number: 1142}, category: mariadb.CategoryPrivileges},
+		{name: "too many connections", err: &mysql.MySQLError{Number: 1040}, category: mariadb.CategoryTooManyConnections},
+		{name: "read only", err: &mysql.MySQLError{Number: 1290}, category: mariadb.CategoryReadOnly},
+		{name: "lock wait timeout", err: &mysql.MySQLError{Number: 1205}, category: mariadb.CategoryLock},
+		{name: "deadlock", err: &mysql.MySQLError{Number: 1213}, category: mariadb.CategoryLock},
+		{name: "table full", err: &mysql.MySQLError{Number: 1114}, category: mariadb.CategoryDiskFull},
+		{name: "disk full", err: &mysql.MySQLError{Number: 1021}, category: mariadb.CategoryDiskFull},
+		{
+			name:     "engine ENOSPC",
+			err:      &mysql.MySQLError{Number: 1030, Message: "Got error 28 \"No space left on device\" from storage engine Aria"},
+			category: mariadb.CategoryDiskFull,
+		},
+		{name: "other engine error", err: &mysql.MySQLError{Number: 1030, Message: "Got error 128 from storage engine"}, category: mariadb.CategoryUnknown},
+		{name: "unknown table", err: &mysql.MySQLError{Number: 1146}, category: mariadb.CategoryUnknownTable},
+		{name: "unlisted number", err: &mysql.MySQLError{Number: 1064}, category: mariadb.CategoryUnknown},
+		{name: "timeout", err: context.DeadlineExceeded, category: mariadb.CategoryNetwork},
+		{name: "bad connection", err: driver.ErrBadConn, category: mariadb.CategoryNetwork},
+		{name: "invalid connection", err: mysql.ErrInvalidConn, category: mariadb.CategoryNetwork},
+		{name: "dial error", err: &net.OpError{Op: "dial", Err: errors.New("refused")}, category: mariadb.CategoryNetwork},
+		{name: "wrapped", err: fmt.Errorf("%w: %w", mariadb.ErrInsert, &mysql.MySQLError{Number: 1290}), category: mariadb.CategoryReadOnly},
+		{name: "plain error", err: errors.New("boom"), category: mariadb.CategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run("should classify "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.category, mariadb.Classify(tt.err))
+		})
+	}
+
+	t.Run("should return empty category for nil", func(t *testing.T) {
+		assert.Empty(t, mariadb.Classify(nil))
+	})
+
+	t.Run("should keep the driver error through RunCheck", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs("id").
+			WillReturnError(&mysql.MySQLError{Number: 1290, Message: "read-only"})
+
+		_, err = mariadb.RunCheck(t.Context(), db, mariadb.DefaultTable, "id", true, 0)
+
+		require.ErrorIs(t, err, mariadb.ErrInsert)
+		assert.Equal(t, mariadb.CategoryReadOnly, mariadb.Classify(err))
+	})
+}
+
+func TestIsMisconfiguration(t *testing.T) {
+	t.Run("should flag sidecar configuration faults", func(t *testing.T) {
+		assert.True(t, mariadb.CategoryAuthentication.IsMisconfiguration())
+		assert.True(t, mariadb.CategoryUnknownDatabase.IsMisconfiguration())
+		assert.True(t, mariadb.CategoryPrivileges.IsMisconfiguration())
+		assert.True(t, mariadb.CategoryUnknownTable.IsMisconfiguration())
+	})
+
+	t.Run("should not flag server faults", func(t *testing.T) {
+		assert.False(t, mariadb.CategoryNetwork.IsMisconfiguration())
+		assert.False(t, mariadb.CategoryDiskFull.IsMisconfiguration())
+		assert.False(t, mariadb.CategoryUnknown.IsMisconfiguration())
+	})
+}
@@ -19,6 +19,53 @@ const (
 	dbConnMaxIdleTime = 1 * time.Minute
 )
 
+// Pool sizes the connection pool opened by ConnectDB.
+type Pool struct {
+	ConnectTimeout  time.Duration
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultPool returns a pool sized for one liveness and one readiness probe
+// in flight at a time.
+func DefaultPool() Pool {
+	return Pool{
+		ConnectTimeout:  dbConnectTimeout,
+		MaxOpenConns:    dbMaxOpenConns,
+		MaxIdleConns:    dbMaxIdleConns,
+		ConnMaxLifetime: dbConnMaxLifetime,
+		ConnMaxIdleTime: dbConnMaxIdleTime,
+	}
+}
+
+// Validate validates the pool
+func (p Pool) Validate() error {
+	if p.ConnectTimeout <= 0 {
+		return fmt.Errorf("invalid connect timeout %s: must be positive", p.ConnectTimeout)
+	}
+
+	if p.MaxOpenConns < 1 {
+		return fmt.Errorf("invalid max open connections %d: must be at least 1", p.MaxOpenConns)
+	}
+
+	if p.MaxIdleConns < 0 || p.MaxIdleConns > p.MaxOpenConns {
+		return fmt.Errorf("invalid max idle connections %d: must be between 0 and max open connections %d",
+			p.MaxIdleConns, p.MaxOpenConns)
+	}
+
+	if p.ConnMaxLifetime < 0 {
+		return fmt.Errorf("invalid connection max lifetime %s: must not be negative", p.ConnMaxLifetime)
+	}
+
+	if p.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("invalid connection max idle time %s: must not be negative", p.ConnMaxIdleTime)
+	}
+
+	return nil
+}
+
 // Validate validates the connection
 func (c *Connection) Validate() error {
 	if c.User == "" {
@@ -50,16 +97,28 @@ func (c *Connection) Validate() error {
 		return fmt.Errorf("invalid port: %d", port)
 	}
 
+	if c.Pool != (Pool{}) {
+		if err := c.Pool.Validate(); err != nil {
+			return fmt.Errorf("invalid pool: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // ConnectDB connects to the database using a DSN built via mysql.Config so
-// that special characters in the password are escaped correctly.
+// that special characters in the password are escaped correctly. The zero
+// Pool stands for DefaultPool.
 func (c Connection) ConnectDB() (*sql.DB, error) {
 	if err := c.Validate(); err != nil {
 		return nil, fmt.Errorf("failed to validate connection: %w", err)
 	}
 
+	pool := c.Pool
+	if pool == (Pool{}) {
+		pool = DefaultPool()
+	}
+
 	cfg := mysql.NewConfig()
 	cfg.User = c.User
 	cfg.Passwd = c.Password
@@ -67,17 +126,17 @@ func (c Connection) ConnectDB() (*sql.DB, error) {
 	cfg.Addr = net.JoinHostPort(c.Host, c.Port)
 	cfg.DBName = c.Database
 	cfg.ParseTime = true
-	cfg.Timeout = dbConnectTimeout
+	cfg.Timeout = pool.ConnectTimeout
 
 	db, err := sql.Open(c.Driver, cfg.FormatDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	db.SetMaxOpenConns(dbMaxOpenConns)
-	db.SetMaxIdleConns(dbMaxIdleConns)
-	db.SetConnMaxLifetime(dbConnMaxLifetime)
-	db.SetConnMaxIdleTime(dbConnMaxIdleTime)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	return db, nil
 }
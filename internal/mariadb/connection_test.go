@@ -3,6 +3,7 @@ package mariadb_test
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
@@ -91,6 +92,44 @@ func TestValidate(t *testing.T) {
 		require.Error(t, err)
 		assert.ErrorContains(t, err, "invalid port")
 	})
+
+	t.Run("should return error if the pool is invalid", func(t *testing.T) {
+		pool := mariadb.DefaultPool()
+		pool.MaxIdleConns = 3
+
+		err := (&mariadb.Connection{
+			User:     "user",
+			Password: "password",
+			Host:     "host",
+			Port:     "3306",
+			Database: "database",
+			Pool:     pool,
+		}).Validate()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid max idle connections 3")
+	})
+}
+
+func TestPoolValidate(t *testing.T) {
+	t.Run("should accept the default pool", func(t *testing.T) {
+		assert.NoError(t, mariadb.DefaultPool().Validate())
+	})
+
+	t.Run("should reject invalid sizes and durations", func(t *testing.T) {
+		for message, change := range map[string]func(*mariadb.Pool){
+			"invalid connect timeout":          func(p *mariadb.Pool) { p.ConnectTimeout = 0 },
+			"invalid max open connections":     func(p *mariadb.Pool) { p.MaxOpenConns = 0 },
+			"invalid max idle connections":     func(p *mariadb.Pool) { p.MaxIdleConns = -1 },
+			"invalid connection max lifetime":  func(p *mariadb.Pool) { p.ConnMaxLifetime = -time.Second },
+			"invalid connection max idle time": func(p *mariadb.Pool) { p.ConnMaxIdleTime = -time.Second },
+		} {
+			pool := mariadb.DefaultPool()
+			change(&pool)
+
+			assert.ErrorContains(t, pool.Validate(), message)
+		}
+	})
 }
 
 func TestConnectDB_DSN_handlesSpecialChars(t *testing.T) {
@@ -112,6 +151,27 @@ func TestConnectDB_DSN_handlesSpecialChars(t *testing.T) {
 	assert.Equal(t, 2, stats.MaxOpenConnections, "MaxOpenConns should be 2")
 }
 
+func TestConnectDB_pool(t *testing.T) {
+	pool := mariadb.DefaultPool()
+	pool.MaxOpenConns = 4
+
+	conn := mariadb.Connection{
+		Driver:   "mysql",
+		Database: "healthcheck",
+		Host:     "127.0.0.1",
+		Password: "password",
+		Port:     "3306",
+		User:     "user",
+		Pool:     pool,
+	}
+
+	db, err := conn.ConnectDB()
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 4, db.Stats().MaxOpenConnections)
+}
+
 func TestConnectDB_DSN_passwordRoundTrip(t *testing.T) {
 	cfg := mysql.NewConfig()
 	cfg.User = "user"
@@ -0,0 +1,144 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// CheckConnections is the name of the connection saturation check.
+const CheckConnections = "connections"
+
+const percent = 100
+
+// ConnectionThresholds are the share of max_connections in use, in percent,
+// at which the connection check turns degraded or unhealthy.
+type ConnectionThresholds struct {
+	DegradedPercent  float64
+	UnhealthyPercent float64
+}
+
+// Validate validates the thresholds.
+func (t ConnectionThresholds) Validate() error {
+	if t.DegradedPercent <= 0 || t.DegradedPercent > percent {
+		return fmt.Errorf("invalid degraded percent: %v", t.DegradedPercent)
+	}
+
+	if t.UnhealthyPercent <= 0 || t.UnhealthyPercent > percent {
+		return fmt.Errorf("invalid unhealthy percent: %v", t.UnhealthyPercent)
+	}
+
+	if t.DegradedPercent > t.UnhealthyPercent {
+		return fmt.Errorf("degraded percent %v is above unhealthy percent %v", t.DegradedPercent, t.UnhealthyPercent)
+	}
+
+	return nil
+}
+
+// ConnectionCheck compares the connections in use with max_connections. A
+// saturated server only fails readiness: restarting MariaDB is the wrong
+// remedy for a connection storm from the application tier.
+type ConnectionCheck struct {
+	Thresholds ConnectionThresholds
+	// Window is the minimum time refused connections are counted over.
+	// Checks within the window of the last count report it again.
+	Window time.Duration
+
+	refused counters
+}
+
+// NewConnectionCheck returns a connection saturation check.
+func NewConnectionCheck(thresholds ConnectionThresholds) *ConnectionCheck {
+	return &ConnectionCheck{Thresholds: thresholds, Window: DefaultCounterWindow}
+}
+
+// Name implements health.Checker.
+func (c *ConnectionCheck) Name() string {
+	return CheckConnections
+}
+
+// Check implements health.Checker.
+func (c *ConnectionCheck) Check(ctx context.Context, db health.DB) health.Result {
+	start := time.Now()
+	result := c.check(ctx, db)
+	result.Name = CheckConnections
+	result.Scope = health.ScopeReadiness
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+func (c *ConnectionCheck) check(ctx context.Context, db health.DB) health.Result {
+	status, err := GlobalStatus(ctx, db,
+		"Threads_connected",
+		"Threads_running",
+		"Max_used_connections",
+		"Connection_errors_max_connections",
+	)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	variables, err := GlobalVariables(ctx, db, "max_connections")
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	var (
+		connected, running, maxUsed, refused, limit uint64
+		errs                                        []error
+	)
+
+	errs = Uints(status, map[string]*uint64{
+		"Threads_connected":                 &connected,
+		"Threads_running":                   &running,
+		"Max_used_connections":              &maxUsed,
+		"Connection_errors_max_connections": &refused,
+	})
+
+	limit, err = Uint(variables, "max_connections")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 || limit == 0 {
+		return health.Result{
+			Status:  health.StatusUnhealthy,
+			Message: fmt.Sprintf("failed to read connection counters: %v", errs),
+		}
+	}
+
+	used := float64(connected) / float64(limit) * percent
+	grown, elapsed, _ := c.refused.since(map[string]uint64{"Connection_errors_max_connections": refused}, c.Window)
+	delta := grown["Connection_errors_max_connections"]
+
+	result := health.Result{
+		Status: health.StatusHealthy,
+		Details: map[string]any{
+			"threadsConnected":               connected,
+			"threadsRunning":                 running,
+			"maxUsedConnections":             maxUsed,
+			"maxConnections":                 limit,
+			"usedPercent":                    used,
+			"connectionErrorsMaxConnections": refused,
+			"refusedInWindow":                delta,
+			"windowSeconds":                  elapsed.Seconds(),
+		},
+	}
+
+	switch {
+	case used >= c.Thresholds.UnhealthyPercent:
+		result.Status = health.StatusUnhealthy
+		result.Message = fmt.Sprintf("%.0f%% of max_connections in use (%d/%d)", used, connected, limit)
+	case used >= c.Thresholds.DegradedPercent:
+		result.Status = health.StatusDegraded
+		result.Message = fmt.Sprintf("%.0f%% of max_connections in use (%d/%d)", used, connected, limit)
+	case delta > 0:
+		result.Status = health.StatusDegraded
+		result.Message = fmt.Sprintf("%d connections refused by max_connections in %s", delta, lastWindow(elapsed))
+	}
+
+	return result
+}
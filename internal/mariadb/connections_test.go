@@ -0,0 +1,131 @@
+package mariadb_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	connectionStatusQuery = "SHOW GLOBAL STATUS WHERE Variable_name IN " +
+		"('Threads_connected', 'Threads_running', 'Max_used_connections', 'Connection_errors_max_connections')"
+	connectionVariablesQuery = "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('max_connections')"
+)
+
+func expectConnections(mock sqlmock.Sqlmock, connected, refused, limit int) {
+	mock.ExpectQuery(connectionStatusQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Threads_connected", strconv.Itoa(connected)).
+			AddRow("Threads_running", "1").
+			AddRow("Max_used_connections", strconv.Itoa(connected)).
+			AddRow("Connection_errors_max_connections", strconv.Itoa(refused)))
+	mock.ExpectQuery(connectionVariablesQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("max_connections", strconv.Itoa(limit)))
+}
+
+func TestConnectionCheck(t *testing.T) {
+	thresholds := mariadb.ConnectionThresholds{DegradedPercent: 80, UnhealthyPercent: 95}
+
+	tests := []struct {
+		name      string
+		connected int
+		status    health.Status
+		message   string
+	}{
+		{name: "should be healthy below thresholds", connected: 10, status: health.StatusHealthy},
+		{name: "should be degraded above degraded threshold", connected: 85, status: health.StatusDegraded, message: "85% of max_connections in use (85/100)"},
+		{name: "should be unhealthy above unhealthy threshold", connected: 99, status: health.StatusUnhealthy, message: "99% of max_connections in use (99/100)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			expectConnections(mock, tt.connected, 0, 100)
+
+			result := mariadb.NewConnectionCheck(thresholds).Check(t.Context(), db)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, mariadb.CheckConnections, result.Name)
+			assert.Equal(t, health.ScopeReadiness, result.Scope)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+			assert.Equal(t, uint64(100), result.Details["maxConnections"])
+		})
+	}
+
+	t.Run("should be degraded when connections were refused in the window", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectConnections(mock, 10, 5, 100)
+		expectConnections(mock, 10, 8, 100)
+
+		check := mariadb.NewConnectionCheck(thresholds)
+		check.Window = 0
+
+		first := check.Check(t.Context(), db)
+		assert.Equal(t, health.StatusHealthy, first.Status)
+
+		second := check.Check(t.Context(), db)
+		assert.Equal(t, health.StatusDegraded, second.Status)
+		assert.Equal(t, "3 connections refused by max_connections in the last 1s", second.Message)
+		assert.Equal(t, uint64(3), second.Details["refusedInWindow"])
+	})
+
+	t.Run("should be unhealthy when status cannot be read", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(connectionStatusQuery).WillReturnError(errors.New("boom"))
+
+		result := mariadb.NewConnectionCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Contains(t, result.Message, "boom")
+	})
+
+	t.Run("should be unhealthy when a counter is missing", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(connectionStatusQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+		mock.ExpectQuery(connectionVariablesQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+
+		result := mariadb.NewConnectionCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Contains(t, result.Message, "failed to read connection counters")
+	})
+}
+
+func TestConnectionThresholdsValidate(t *testing.T) {
+	t.Run("should accept valid thresholds", func(t *testing.T) {
+		assert.NoError(t, mariadb.ConnectionThresholds{DegradedPercent: 80, UnhealthyPercent: 95}.Validate())
+	})
+
+	t.Run("should reject out of range thresholds", func(t *testing.T) {
+		assert.Error(t, mariadb.ConnectionThresholds{DegradedPercent: 0, UnhealthyPercent: 95}.Validate())
+		assert.Error(t, mariadb.ConnectionThresholds{DegradedPercent: 80, UnhealthyPercent: 120}.Validate())
+	})
+
+	t.Run("should reject degraded above unhealthy", func(t *testing.T) {
+		err := mariadb.ConnectionThresholds{DegradedPercent: 90, UnhealthyPercent: 80}.Validate()
+
+		assert.ErrorContains(t, err, "above unhealthy percent")
+	})
+}
@@ -0,0 +1,74 @@
+package mariadb
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultCounterWindow is the default minimum time between the two readings
+// of cumulative counters a check compares.
+const DefaultCounterWindow = 10 * time.Second
+
+// counters remembers a reading of cumulative server counters, so a check can
+// report how much they grew since. Every probe runs every check, so readings
+// closer together than the window are not compared: a sub-second window turns
+// a single event into a huge rate, and would hide a delta already consumed by
+// another probe. They get the last comparison instead.
+type counters struct {
+	mu      sync.Mutex
+	last    map[string]uint64
+	at      time.Time
+	deltas  map[string]uint64
+	elapsed time.Duration
+	ok      bool
+}
+
+// since returns how much every counter grew between the baseline and
+// current, and the time in between, then makes current the new baseline.
+// Within window of the baseline it returns the previous comparison and keeps
+// the baseline. The first reading and a counter going backwards (server
+// restart) return false and start over. A counter missing from the baseline
+// is left out of the deltas.
+func (c *counters) since(current map[string]uint64, window time.Duration) (map[string]uint64, time.Duration, bool) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && now.Sub(c.at) < window {
+		return c.deltas, c.elapsed, c.ok
+	}
+
+	previous, at := c.last, c.at
+	c.last, c.at = current, now
+	c.deltas, c.elapsed, c.ok = nil, 0, false
+
+	if previous == nil {
+		return nil, 0, false
+	}
+
+	deltas := make(map[string]uint64, len(current))
+
+	for name, n := range current {
+		before, ok := previous[name]
+		if !ok {
+			continue
+		}
+
+		if n < before {
+			return nil, 0, false
+		}
+
+		deltas[name] = n - before
+	}
+
+	c.deltas, c.elapsed, c.ok = deltas, now.Sub(at), true
+
+	return c.deltas, c.elapsed, c.ok
+}
+
+// lastWindow describes the time a comparison covers for a message, e.g.
+// "the last 10s", in whole seconds and at least one.
+func lastWindow(elapsed time.Duration) string {
+	return "the last " + max(elapsed.Round(time.Second), time.Second).String()
+}
@@ -0,0 +1,379 @@
+package mariadb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// CheckInnoDB is the name of the InnoDB engine check.
+const CheckInnoDB = "innodb"
+
+// unknownMetric is the detail of an INNODB_METRICS counter that is disabled.
+const unknownMetric = "unknown"
+
+var (
+	logSequenceNumber = regexp.MustCompile(`Log sequence number\s+(\d+)`)
+	lastCheckpoint    = regexp.MustCompile(`Last checkpoint at\s+(\d+)`)
+)
+
+// InnoDBThresholds are the levels at which the InnoDB check turns degraded.
+type InnoDBThresholds struct {
+	// HistoryLength is the undo history list length, i.e. purge lag.
+	HistoryLength uint64
+	// CheckpointAgePercent is the share of the redo log capacity not yet
+	// checkpointed. Writes stall when it runs full.
+	CheckpointAgePercent float64
+	// PendingIO is the number of pending data reads, writes and fsyncs.
+	PendingIO uint64
+	// BufferPoolHitPercent is the buffer pool hit ratio since the previous
+	// comparison below which the check is degraded.
+	BufferPoolHitPercent float64
+	// Deadlocks is the number of deadlocks since the previous comparison.
+	Deadlocks uint64
+}
+
+// Validate validates the thresholds.
+func (t InnoDBThresholds) Validate() error {
+	if t.HistoryLength == 0 {
+		return fmt.Errorf("invalid history length: %d", t.HistoryLength)
+	}
+
+	if t.CheckpointAgePercent <= 0 || t.CheckpointAgePercent > percent {
+		return fmt.Errorf("invalid checkpoint age percent: %v", t.CheckpointAgePercent)
+	}
+
+	if t.PendingIO == 0 {
+		return fmt.Errorf("invalid pending I/O: %d", t.PendingIO)
+	}
+
+	if t.BufferPoolHitPercent <= 0 || t.BufferPoolHitPercent > percent {
+		return fmt.Errorf("invalid buffer pool hit percent: %v", t.BufferPoolHitPercent)
+	}
+
+	if t.Deadlocks == 0 {
+		return fmt.Errorf("invalid deadlocks: %d", t.Deadlocks)
+	}
+
+	return nil
+}
+
+// InnoDBCheck watches the engine that holds the real data. The round-trip
+// usually writes to a MEMORY table and never exercises InnoDB, so purge lag,
+// a full redo log, an I/O backlog, a cold buffer pool or a deadlock storm go
+// unnoticed otherwise. These only degrade readiness; it fails readiness when
+// the counters cannot be read.
+type InnoDBCheck struct {
+	Thresholds InnoDBThresholds
+	// Window is the minimum time the buffer pool hit ratio and the deadlocks
+	// are counted over. Checks within the window of the last comparison
+	// report it again.
+	Window time.Duration
+
+	counters counters
+}
+
+// NewInnoDBCheck returns an InnoDB engine check.
+func NewInnoDBCheck(thresholds InnoDBThresholds) *InnoDBCheck {
+	return &InnoDBCheck{Thresholds: thresholds, Window: DefaultCounterWindow}
+}
+
+// Name implements health.Checker.
+func (c *InnoDBCheck) Name() string {
+	return CheckInnoDB
+}
+
+// Check implements health.Checker.
+func (c *InnoDBCheck) Check(ctx context.Context, db health.DB) health.Result {
+	start := time.Now()
+	result := c.check(ctx, db)
+	result.Name = CheckInnoDB
+	result.Scope = health.ScopeReadiness
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+func (c *InnoDBCheck) check(ctx context.Context, db health.DB) health.Result {
+	innodbMetrics, err := InnoDBMetrics(ctx, db, "trx_rseg_history_len", "lock_deadlocks")
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	lsn, checkpoint, err := CheckpointLSNs(ctx, db)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	status, err := GlobalStatus(ctx, db,
+		"Innodb_data_pending_reads",
+		"Innodb_data_pending_writes",
+		"Innodb_data_pending_fsyncs",
+		"Innodb_buffer_pool_read_requests",
+		"Innodb_buffer_pool_reads",
+	)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	variables, err := GlobalVariables(ctx, db, "innodb_log_file_size", "innodb_log_files_in_group")
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	var (
+		history, pendingReads, pendingWrites, pendingFsyncs, logFileSize uint64
+		readRequests, reads, deadlocks                                   uint64
+		errs                                                             []error
+	)
+
+	_, historyKnown := innodbMetrics["trx_rseg_history_len"]
+	_, deadlocksKnown := innodbMetrics["lock_deadlocks"]
+
+	if historyKnown {
+		errs = append(errs, Uints(innodbMetrics, map[string]*uint64{"trx_rseg_history_len": &history})...)
+	}
+
+	if deadlocksKnown {
+		errs = append(errs, Uints(innodbMetrics, map[string]*uint64{"lock_deadlocks": &deadlocks})...)
+	}
+
+	errs = append(errs, Uints(status, map[string]*uint64{
+		"Innodb_data_pending_reads":        &pendingReads,
+		"Innodb_data_pending_writes":       &pendingWrites,
+		"Innodb_data_pending_fsyncs":       &pendingFsyncs,
+		"Innodb_buffer_pool_read_requests": &readRequests,
+		"Innodb_buffer_pool_reads":         &reads,
+	})...)
+	errs = append(errs, Uints(variables, map[string]*uint64{
+		"innodb_log_file_size": &logFileSize,
+	})...)
+
+	if len(errs) > 0 {
+		return health.Result{
+			Status:  health.StatusUnhealthy,
+			Message: fmt.Sprintf("failed to read InnoDB counters: %v", errs),
+		}
+	}
+
+	// MariaDB 10.6 and later have a single redo log file.
+	files, err := Uint(variables, "innodb_log_files_in_group")
+	if err != nil {
+		files = 1
+	}
+
+	capacity := logFileSize * files
+	pending := pendingReads + pendingWrites + pendingFsyncs
+
+	var age uint64
+	if lsn > checkpoint {
+		age = lsn - checkpoint
+	}
+
+	details := map[string]any{
+		"historyListLength": unknownMetric,
+		"checkpointAge":     age,
+		"redoLogCapacity":   capacity,
+		"pendingReads":      pendingReads,
+		"pendingWrites":     pendingWrites,
+		"pendingFsyncs":     pendingFsyncs,
+		"deadlocks":         unknownMetric,
+	}
+
+	var problems []string
+
+	if historyKnown {
+		details["historyListLength"] = history
+
+		if history >= c.Thresholds.HistoryLength {
+			problems = append(problems, fmt.Sprintf("history list length is %d", history))
+		}
+	}
+
+	if deadlocksKnown {
+		details["deadlocks"] = deadlocks
+	}
+
+	if capacity > 0 {
+		used := float64(age) / float64(capacity) * percent
+		details["checkpointAgePercent"] = used
+
+		if used >= c.Thresholds.CheckpointAgePercent {
+			problems = append(problems, fmt.Sprintf("checkpoint age is %.0f%% of the redo log", used))
+		}
+	}
+
+	if pending >= c.Thresholds.PendingIO {
+		problems = append(problems, fmt.Sprintf("%d I/O operations pending", pending))
+	}
+
+	current := map[string]uint64{
+		"Innodb_buffer_pool_read_requests": readRequests,
+		"Innodb_buffer_pool_reads":         reads,
+	}
+
+	if deadlocksKnown {
+		current["lock_deadlocks"] = deadlocks
+	}
+
+	deltas, elapsed, ok := c.counters.since(current, c.Window)
+	if ok {
+		problems = append(problems, c.deltas(deltas, elapsed, details)...)
+	}
+
+	result := health.Result{Status: health.StatusHealthy, Details: details}
+
+	if len(problems) > 0 {
+		result.Status = health.StatusDegraded
+		result.Message = strings.Join(problems, "; ")
+	}
+
+	return result
+}
+
+// deltas judges the growth of the counters over the window.
+func (c *InnoDBCheck) deltas(deltas map[string]uint64, elapsed time.Duration, details map[string]any) []string {
+	var problems []string
+
+	if requests := deltas["Innodb_buffer_pool_read_requests"]; requests > 0 {
+		hit := (1 - float64(deltas["Innodb_buffer_pool_reads"])/float64(requests)) * percent
+		details["bufferPoolHitPercent"] = hit
+
+		if hit < c.Thresholds.BufferPoolHitPercent {
+			problems = append(problems, fmt.Sprintf("buffer pool hit ratio is %.1f%%", hit))
+		}
+	}
+
+	details["windowSeconds"] = elapsed.Seconds()
+
+	deadlocks, ok := deltas["lock_deadlocks"]
+	if !ok {
+		details["deadlocksInWindow"] = unknownMetric
+
+		return problems
+	}
+
+	details["deadlocksInWindow"] = deadlocks
+
+	if deadlocks >= c.Thresholds.Deadlocks {
+		problems = append(problems, fmt.Sprintf("%d deadlocks in %s", deadlocks, lastWindow(elapsed)))
+	}
+
+	return problems
+}
+
+// InnoDBMetrics returns the COUNT of the named information_schema.INNODB_METRICS
+// counters keyed by their lower-cased name. Missing and disabled counters are
+// absent from the map, as the COUNT of a disabled counter stopped growing.
+// The enabled state is read from the STATUS column, which MariaDB 10.5
+// replaced with ENABLED.
+func InnoDBMetrics(ctx context.Context, db health.DB, names ...string) (map[string]string, error) {
+	args := make([]any, 0, len(names))
+	for _, name := range names {
+		args = append(args, name)
+	}
+
+	query := "SELECT * FROM information_schema.INNODB_METRICS WHERE NAME IN (?" +
+		strings.Repeat(", ?", max(len(names)-1, 0)) + ")"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("INNODB_METRICS: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("INNODB_METRICS: %w", err)
+	}
+
+	raw := make([]sql.RawBytes, len(columns))
+	dst := make([]any, len(columns))
+
+	for i := range raw {
+		dst[i] = &raw[i]
+	}
+
+	values := make(map[string]string, len(names))
+
+	for rows.Next() {
+		if err := rows.Scan(dst...); err != nil {
+			return nil, fmt.Errorf("INNODB_METRICS: %w", err)
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[strings.ToLower(column)] = string(raw[i])
+		}
+
+		if !metricEnabled(row) {
+			continue
+		}
+
+		values[strings.ToLower(row["name"])] = row["count"]
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("INNODB_METRICS: %w", err)
+	}
+
+	return values, nil
+}
+
+// metricEnabled reports whether an INNODB_METRICS row is enabled, under the
+// STATUS column ("enabled") or the MariaDB 10.5 ENABLED column (1). A row
+// reporting neither counts as enabled.
+func metricEnabled(row map[string]string) bool {
+	if status, ok := row["status"]; ok {
+		return strings.EqualFold(status, "enabled")
+	}
+
+	if enabled, ok := row["enabled"]; ok {
+		return enabled == "1"
+	}
+
+	return true
+}
+
+// CheckpointLSNs returns the current log sequence number and the one of the
+// last checkpoint from SHOW ENGINE INNODB STATUS.
+func CheckpointLSNs(ctx context.Context, db health.DB) (uint64, uint64, error) {
+	var engine, name, status string
+
+	err := db.QueryRowContext(ctx, "SHOW ENGINE INNODB STATUS").Scan(&engine, &name, &status)
+	if err != nil {
+		return 0, 0, fmt.Errorf("SHOW ENGINE INNODB STATUS: %w", err)
+	}
+
+	lsn, err := matchUint(logSequenceNumber, status)
+	if err != nil {
+		return 0, 0, fmt.Errorf("SHOW ENGINE INNODB STATUS: log sequence number: %w", err)
+	}
+
+	checkpoint, err := matchUint(lastCheckpoint, status)
+	if err != nil {
+		return 0, 0, fmt.Errorf("SHOW ENGINE INNODB STATUS: last checkpoint: %w", err)
+	}
+
+	return lsn, checkpoint, nil
+}
+
+func matchUint(pattern *regexp.Regexp, text string) (uint64, error) {
+	match := pattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, fmt.Errorf("not reported by the server")
+	}
+
+	n, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %w", err)
+	}
+
+	return n, nil
+}
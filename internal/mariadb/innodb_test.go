@@ -0,0 +1,247 @@
+package mariadb_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	innodbMetricsQuery = "SELECT * FROM information_schema.INNODB_METRICS WHERE NAME IN (?, ?)"
+	innodbStatusQuery  = "SHOW GLOBAL STATUS WHERE Variable_name IN ('Innodb_data_pending_reads', " +
+		"'Innodb_data_pending_writes', 'Innodb_data_pending_fsyncs', " +
+		"'Innodb_buffer_pool_read_requests', 'Innodb_buffer_pool_reads')"
+	innodbVariablesQuery = "SHOW GLOBAL VARIABLES WHERE Variable_name IN " +
+		"('innodb_log_file_size', 'innodb_log_files_in_group')"
+)
+
+type innodbCounters struct {
+	history, deadlocks, lsn, checkpoint, pending, readRequests, reads int
+}
+
+func expectInnoDB(mock sqlmock.Sqlmock, c innodbCounters) {
+	mock.ExpectQuery(innodbMetricsQuery).
+		WithArgs("trx_rseg_history_len", "lock_deadlocks").
+		WillReturnRows(sqlmock.NewRows([]string{"NAME", "SUBSYSTEM", "COUNT", "STATUS"}).
+			AddRow("trx_rseg_history_len", "transaction", c.history, "enabled").
+			AddRow("lock_deadlocks", "lock", c.deadlocks, "enabled"))
+	expectInnoDBRest(mock, c)
+}
+
+// expectInnoDBRest expects the queries of the InnoDB check after
+// INNODB_METRICS.
+func expectInnoDBRest(mock sqlmock.Sqlmock, c innodbCounters) {
+	mock.ExpectQuery("SHOW ENGINE INNODB STATUS").
+		WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).
+			AddRow("InnoDB", "", fmt.Sprintf("---\nLOG\n---\nLog sequence number %d\n"+
+				"Log flushed up to   %d\nPages flushed up to %d\nLast checkpoint at  %d\n",
+				c.lsn, c.lsn, c.checkpoint, c.checkpoint)))
+	mock.ExpectQuery(innodbStatusQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Innodb_data_pending_reads", strconv.Itoa(c.pending)).
+			AddRow("Innodb_data_pending_writes", "0").
+			AddRow("Innodb_data_pending_fsyncs", "0").
+			AddRow("Innodb_buffer_pool_read_requests", strconv.Itoa(c.readRequests)).
+			AddRow("Innodb_buffer_pool_reads", strconv.Itoa(c.reads)))
+	mock.ExpectQuery(innodbVariablesQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("innodb_log_file_size", "1000"))
+}
+
+func TestInnoDBCheck(t *testing.T) {
+	thresholds := mariadb.InnoDBThresholds{
+		HistoryLength:        1000,
+		CheckpointAgePercent: 75,
+		PendingIO:            64,
+		BufferPoolHitPercent: 95,
+		Deadlocks:            10,
+	}
+
+	tests := []struct {
+		name    string
+		first   innodbCounters
+		second  innodbCounters
+		status  health.Status
+		message string
+	}{
+		{
+			name:   "should be healthy when the engine keeps up",
+			first:  innodbCounters{history: 10, lsn: 5000, checkpoint: 4900, readRequests: 1000, reads: 1},
+			second: innodbCounters{history: 20, lsn: 6000, checkpoint: 5900, readRequests: 2000, reads: 2},
+			status: health.StatusHealthy,
+		},
+		{
+			name:    "should be degraded when purge lags behind",
+			first:   innodbCounters{history: 10},
+			second:  innodbCounters{history: 5000},
+			status:  health.StatusDegraded,
+			message: "history list length is 5000",
+		},
+		{
+			name:    "should be degraded when the redo log is nearly full",
+			first:   innodbCounters{lsn: 1000, checkpoint: 900},
+			second:  innodbCounters{lsn: 1900, checkpoint: 1000},
+			status:  health.StatusDegraded,
+			message: "checkpoint age is 90% of the redo log",
+		},
+		{
+			name:    "should be degraded when I/O piles up",
+			second:  innodbCounters{pending: 100},
+			status:  health.StatusDegraded,
+			message: "100 I/O operations pending",
+		},
+		{
+			name:    "should be degraded when the buffer pool misses",
+			first:   innodbCounters{readRequests: 1000, reads: 10},
+			second:  innodbCounters{readRequests: 2000, reads: 210},
+			status:  health.StatusDegraded,
+			message: "buffer pool hit ratio is 80.0%",
+		},
+		{
+			name:    "should be degraded on a deadlock storm",
+			first:   innodbCounters{deadlocks: 3},
+			second:  innodbCounters{deadlocks: 30},
+			status:  health.StatusDegraded,
+			message: "27 deadlocks in the last 1s",
+		},
+		{
+			name:   "should not compare counters after a server restart",
+			first:  innodbCounters{readRequests: 5000, deadlocks: 100},
+			second: innodbCounters{readRequests: 10, reads: 10, deadlocks: 0},
+			status: health.StatusHealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			check := mariadb.NewInnoDBCheck(thresholds)
+			check.Window = 0
+
+			expectInnoDB(mock, tt.first)
+			expectInnoDB(mock, tt.second)
+
+			check.Check(t.Context(), db)
+			result := check.Check(t.Context(), db)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, mariadb.CheckInnoDB, result.Name)
+			assert.Equal(t, health.ScopeReadiness, result.Scope)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+
+	t.Run("should not count deadlocks within the window", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewInnoDBCheck(thresholds)
+
+		expectInnoDB(mock, innodbCounters{lsn: 10, checkpoint: 10, deadlocks: 3})
+		expectInnoDB(mock, innodbCounters{lsn: 10, checkpoint: 10, deadlocks: 30})
+
+		check.Check(t.Context(), db)
+		result := check.Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusHealthy, result.Status)
+		assert.NotContains(t, result.Details, "deadlocksInWindow")
+	})
+
+	t.Run("should report disabled counters as unknown", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewInnoDBCheck(thresholds)
+		check.Window = 0
+
+		for range 2 {
+			mock.ExpectQuery(innodbMetricsQuery).
+				WithArgs("trx_rseg_history_len", "lock_deadlocks").
+				WillReturnRows(sqlmock.NewRows([]string{"NAME", "COUNT", "ENABLED"}).
+					AddRow("trx_rseg_history_len", 5000, 0).
+					AddRow("lock_deadlocks", 0, 0))
+			expectInnoDBRest(mock, innodbCounters{lsn: 10, checkpoint: 10})
+		}
+
+		check.Check(t.Context(), db)
+		result := check.Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, health.StatusHealthy, result.Status)
+		assert.Equal(t, "unknown", result.Details["historyListLength"])
+		assert.Equal(t, "unknown", result.Details["deadlocks"])
+		assert.Equal(t, "unknown", result.Details["deadlocksInWindow"])
+	})
+
+	t.Run("should be unhealthy without the PROCESS privilege", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(innodbMetricsQuery).
+			WithArgs("trx_rseg_history_len", "lock_deadlocks").
+			WillReturnError(errors.New("Error 1227: Access denied; you need the PROCESS privilege"))
+
+		result := mariadb.NewInnoDBCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Contains(t, result.Message, "INNODB_METRICS")
+	})
+
+	t.Run("should be unhealthy when the checkpoint is not reported", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(innodbMetricsQuery).
+			WithArgs("trx_rseg_history_len", "lock_deadlocks").
+			WillReturnRows(sqlmock.NewRows([]string{"NAME", "COUNT"}))
+		mock.ExpectQuery("SHOW ENGINE INNODB STATUS").
+			WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).
+				AddRow("InnoDB", "", "Log sequence number 10\n"))
+
+		result := mariadb.NewInnoDBCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Contains(t, result.Message, "last checkpoint")
+	})
+}
+
+func TestInnoDBThresholdsValidate(t *testing.T) {
+	valid := mariadb.InnoDBThresholds{
+		HistoryLength:        1000000,
+		CheckpointAgePercent: 75,
+		PendingIO:            64,
+		BufferPoolHitPercent: 95,
+		Deadlocks:            10,
+	}
+
+	t.Run("should accept valid thresholds", func(t *testing.T) {
+		assert.NoError(t, valid.Validate())
+	})
+
+	t.Run("should reject out of range thresholds", func(t *testing.T) {
+		invalid := valid
+		invalid.CheckpointAgePercent = 0
+
+		assert.ErrorContains(t, invalid.Validate(), "invalid checkpoint age percent")
+
+		invalid = valid
+		invalid.Deadlocks = 0
+
+		assert.ErrorContains(t, invalid.Validate(), "invalid deadlocks")
+	})
+}
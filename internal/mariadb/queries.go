@@ -3,12 +3,33 @@ package mariadb
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
 )
 
+// DefaultTable is the status table the round-trip uses unless configured
+// otherwise.
+const DefaultTable = "status"
+
+// tableName matches the names of status tables. Names are inlined into the
+// round-trip statements, so anything else is rejected.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsTableName reports whether name can be used as a status table.
+func IsTableName(name string) bool {
+	return tableName.MatchString(name)
+}
+
 // InsertRow inserts a row into the status table for the given value.
-func InsertRow(ctx context.Context, db *sql.DB, value string) error {
-	_, err := db.ExecContext(ctx, "INSERT INTO status (uuid) VALUES (?)", value)
+func InsertRow(ctx context.Context, db health.DB, table, value string) error {
+	if !IsTableName(table) {
+		return fmt.Errorf("InsertRow: invalid table name %q", table)
+	}
+
+	_, err := db.ExecContext(ctx, "INSERT INTO "+table+" (uuid) VALUES (?)", value)
 	if err != nil {
 		return fmt.Errorf("InsertRow: %w", err)
 	}
@@ -17,8 +38,12 @@ func InsertRow(ctx context.Context, db *sql.DB, value string) error {
 }
 
 // SelectRow selects a row from the status table matching the given value.
-func SelectRow(ctx context.Context, db *sql.DB, value string) (*sql.Row, error) {
-	row := db.QueryRowContext(ctx, "SELECT uuid FROM status WHERE uuid = ?", value)
+func SelectRow(ctx context.Context, db health.DB, table, value string) (*sql.Row, error) {
+	if !IsTableName(table) {
+		return nil, fmt.Errorf("SelectRow: invalid table name %q", table)
+	}
+
+	row := db.QueryRowContext(ctx, "SELECT uuid FROM "+table+" WHERE uuid = ?", value)
 	if row.Err() != nil {
 		return nil, fmt.Errorf("SelectRow: %w", row.Err())
 	}
@@ -26,9 +51,30 @@ func SelectRow(ctx context.Context, db *sql.DB, value string) (*sql.Row, error)
 	return row, nil
 }
 
+// ReadRow reads at most one row from the status table. It succeeds on an
+// empty table.
+func ReadRow(ctx context.Context, db health.DB, table string) error {
+	if !IsTableName(table) {
+		return fmt.Errorf("ReadRow: invalid table name %q", table)
+	}
+
+	var value string
+
+	err := db.QueryRowContext(ctx, "SELECT uuid FROM "+table+" LIMIT 1").Scan(&value)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("ReadRow: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteRow deletes a row from the status table matching the given value.
-func DeleteRow(ctx context.Context, db *sql.DB, value string) error {
-	_, err := db.ExecContext(ctx, "DELETE FROM status WHERE uuid = ?", value)
+func DeleteRow(ctx context.Context, db health.DB, table, value string) error {
+	if !IsTableName(table) {
+		return fmt.Errorf("DeleteRow: invalid table name %q", table)
+	}
+
+	_, err := db.ExecContext(ctx, "DELETE FROM "+table+" WHERE uuid = ?", value)
 	if err != nil {
 		return fmt.Errorf("DeleteRow: %w", err)
 	}
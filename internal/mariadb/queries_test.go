@@ -22,13 +22,37 @@ func TestInsertRow(t *testing.T) {
 			WithArgs("1").
 			WillReturnError(errors.New("insert failed"))
 
-		err = mariadb.InsertRow(t.Context(), db, "1")
+		err = mariadb.InsertRow(t.Context(), db, mariadb.DefaultTable, "1")
 
 		require.NoError(t, mock.ExpectationsWereMet())
 		require.Error(t, err)
 		assert.ErrorContains(t, err, "InsertRow")
 	})
 
+	t.Run("should reject an invalid table name", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = mariadb.InsertRow(t.Context(), db, "status; DROP TABLE status", "1")
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.ErrorContains(t, err, "invalid table name")
+	})
+
+	t.Run("should insert into the given table", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status_aria (uuid) VALUES (?)").
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		require.NoError(t, mariadb.InsertRow(t.Context(), db, "status_aria", "1"))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("should insert row successfully", func(t *testing.T) {
 		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
 		if err != nil {
@@ -40,7 +64,7 @@ func TestInsertRow(t *testing.T) {
 			WithArgs("1").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err = mariadb.InsertRow(t.Context(), db, "1")
+		err = mariadb.InsertRow(t.Context(), db, mariadb.DefaultTable, "1")
 
 		require.NoError(t, mock.ExpectationsWereMet())
 		assert.NoError(t, err)
@@ -59,7 +83,7 @@ func TestSelectRow(t *testing.T) {
 			WithArgs("1").
 			WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("1"))
 
-		row, err := mariadb.SelectRow(t.Context(), db, "1")
+		row, err := mariadb.SelectRow(t.Context(), db, mariadb.DefaultTable, "1")
 
 		require.NoError(t, mock.ExpectationsWereMet())
 		assert.NoError(t, err)
@@ -77,7 +101,7 @@ func TestSelectRow(t *testing.T) {
 			WithArgs("1").
 			WillReturnError(errors.New("select failed"))
 
-		_, err = mariadb.SelectRow(t.Context(), db, "1")
+		_, err = mariadb.SelectRow(t.Context(), db, mariadb.DefaultTable, "1")
 
 		require.NoError(t, mock.ExpectationsWereMet())
 		require.Error(t, err)
@@ -97,7 +121,7 @@ func TestDeleteRow(t *testing.T) {
 			WithArgs("1").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err = mariadb.DeleteRow(t.Context(), db, "1")
+		err = mariadb.DeleteRow(t.Context(), db, mariadb.DefaultTable, "1")
 
 		require.NoError(t, mock.ExpectationsWereMet())
 		assert.NoError(t, err)
@@ -114,7 +138,7 @@ func TestDeleteRow(t *testing.T) {
 			WithArgs("1").
 			WillReturnError(errors.New("delete failed"))
 
-		err = mariadb.DeleteRow(t.Context(), db, "1")
+		err = mariadb.DeleteRow(t.Context(), db, mariadb.DefaultTable, "1")
 
 		require.Error(t, err)
 		require.NoError(t, mock.ExpectationsWereMet())
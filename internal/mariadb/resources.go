@@ -0,0 +1,203 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// CheckResources is the name of the server resource saturation check.
+const CheckResources = "resources"
+
+// ResourceThresholds are the levels at which the resource check turns
+// degraded. Rates are per second, averaged over at least the Window of the
+// check.
+type ResourceThresholds struct {
+	// OpenFilesPercent is the share of open_files_limit in use.
+	OpenFilesPercent float64
+	// OpenedTablesPerSecond is the Opened_tables rate tolerated while the
+	// table cache is full.
+	OpenedTablesPerSecond float64
+	// ThreadsCreatedPerSecond is the Threads_created rate, i.e. thread cache
+	// misses.
+	ThreadsCreatedPerSecond float64
+	// TmpDiskTablesPercent is the share of implicit temporary tables created
+	// on disk.
+	TmpDiskTablesPercent float64
+}
+
+// Validate validates the thresholds.
+func (t ResourceThresholds) Validate() error {
+	if t.OpenFilesPercent <= 0 || t.OpenFilesPercent > percent {
+		return fmt.Errorf("invalid open files percent: %v", t.OpenFilesPercent)
+	}
+
+	if t.TmpDiskTablesPercent <= 0 || t.TmpDiskTablesPercent > percent {
+		return fmt.Errorf("invalid tmp disk tables percent: %v", t.TmpDiskTablesPercent)
+	}
+
+	if t.OpenedTablesPerSecond <= 0 {
+		return fmt.Errorf("invalid opened tables rate: %v", t.OpenedTablesPerSecond)
+	}
+
+	if t.ThreadsCreatedPerSecond <= 0 {
+		return fmt.Errorf("invalid threads created rate: %v", t.ThreadsCreatedPerSecond)
+	}
+
+	return nil
+}
+
+// ResourceCheck watches the early signs of a server that is up but falling
+// over: file descriptors running out, a thrashing table cache, thread cache
+// misses and temporary tables spilling to disk. None of these is fixed by
+// restarting MariaDB, so it only degrades readiness; it fails readiness when
+// the counters cannot be read.
+type ResourceCheck struct {
+	Thresholds ResourceThresholds
+	// Window is the minimum time the rates are averaged over. Checks within
+	// the window of the last comparison report its rates again.
+	Window time.Duration
+
+	counters counters
+}
+
+// NewResourceCheck returns a server resource saturation check.
+func NewResourceCheck(thresholds ResourceThresholds) *ResourceCheck {
+	return &ResourceCheck{Thresholds: thresholds, Window: DefaultCounterWindow}
+}
+
+// Name implements health.Checker.
+func (c *ResourceCheck) Name() string {
+	return CheckResources
+}
+
+// Check implements health.Checker.
+func (c *ResourceCheck) Check(ctx context.Context, db health.DB) health.Result {
+	start := time.Now()
+	result := c.check(ctx, db)
+	result.Name = CheckResources
+	result.Scope = health.ScopeReadiness
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+func (c *ResourceCheck) check(ctx context.Context, db health.DB) health.Result {
+	status, err := GlobalStatus(ctx, db,
+		"Open_files",
+		"Open_tables",
+		"Opened_tables",
+		"Threads_created",
+		"Created_tmp_tables",
+		"Created_tmp_disk_tables",
+	)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	variables, err := GlobalVariables(ctx, db, "open_files_limit", "table_open_cache")
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	var (
+		openFiles, openTables, filesLimit, tableCache          uint64
+		openedTables, threadsCreated, tmpTables, tmpDiskTables uint64
+		errs                                                   []error
+	)
+
+	errs = append(errs, Uints(status, map[string]*uint64{
+		"Open_files":              &openFiles,
+		"Open_tables":             &openTables,
+		"Opened_tables":           &openedTables,
+		"Threads_created":         &threadsCreated,
+		"Created_tmp_tables":      &tmpTables,
+		"Created_tmp_disk_tables": &tmpDiskTables,
+	})...)
+	errs = append(errs, Uints(variables, map[string]*uint64{
+		"open_files_limit": &filesLimit,
+		"table_open_cache": &tableCache,
+	})...)
+
+	if len(errs) > 0 {
+		return health.Result{
+			Status:  health.StatusUnhealthy,
+			Message: fmt.Sprintf("failed to read resource counters: %v", errs),
+		}
+	}
+
+	details := map[string]any{
+		"openFiles":      openFiles,
+		"openFilesLimit": filesLimit,
+		"openTables":     openTables,
+		"tableOpenCache": tableCache,
+	}
+
+	var problems []string
+
+	if filesLimit > 0 {
+		used := float64(openFiles) / float64(filesLimit) * percent
+		details["openFilesPercent"] = used
+
+		if used >= c.Thresholds.OpenFilesPercent {
+			problems = append(problems, fmt.Sprintf("%.0f%% of open_files_limit in use (%d/%d)", used, openFiles, filesLimit))
+		}
+	}
+
+	deltas, elapsed, ok := c.counters.since(map[string]uint64{
+		"Opened_tables":           openedTables,
+		"Threads_created":         threadsCreated,
+		"Created_tmp_tables":      tmpTables,
+		"Created_tmp_disk_tables": tmpDiskTables,
+	}, c.Window)
+	if ok {
+		problems = append(problems, c.rates(deltas, elapsed.Seconds(), openTables >= tableCache, details)...)
+	}
+
+	result := health.Result{Status: health.StatusHealthy, Details: details}
+
+	if len(problems) > 0 {
+		result.Status = health.StatusDegraded
+		result.Message = strings.Join(problems, "; ")
+	}
+
+	return result
+}
+
+// rates derives the rates from the growth of the counters over elapsed
+// seconds.
+func (c *ResourceCheck) rates(deltas map[string]uint64, elapsed float64, cacheFull bool, details map[string]any) []string {
+	if elapsed <= 0 {
+		return nil
+	}
+
+	var problems []string
+
+	opened := float64(deltas["Opened_tables"]) / elapsed
+	details["openedTablesPerSecond"] = opened
+
+	if cacheFull && opened >= c.Thresholds.OpenedTablesPerSecond {
+		problems = append(problems, fmt.Sprintf("table cache is full and %.1f tables/s are opened", opened))
+	}
+
+	created := float64(deltas["Threads_created"]) / elapsed
+	details["threadsCreatedPerSecond"] = created
+
+	if created >= c.Thresholds.ThreadsCreatedPerSecond {
+		problems = append(problems, fmt.Sprintf("%.1f threads/s created, thread cache too small", created))
+	}
+
+	if tmp := deltas["Created_tmp_tables"]; tmp > 0 {
+		onDisk := float64(deltas["Created_tmp_disk_tables"]) / float64(tmp) * percent
+		details["tmpDiskTablesPercent"] = onDisk
+
+		if onDisk >= c.Thresholds.TmpDiskTablesPercent {
+			problems = append(problems, fmt.Sprintf("%.0f%% of temporary tables created on disk", onDisk))
+		}
+	}
+
+	return problems
+}
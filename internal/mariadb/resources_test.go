@@ -0,0 +1,221 @@
+package mariadb_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	resourceStatusQuery = "SHOW GLOBAL STATUS WHERE Variable_name IN ('Open_files', 'Open_tables', " +
+		"'Opened_tables', 'Threads_created', 'Created_tmp_tables', 'Created_tmp_disk_tables')"
+	resourceVariablesQuery = "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('open_files_limit', 'table_open_cache')"
+)
+
+type resourceCounters struct {
+	openFiles, openTables, openedTables, threadsCreated, tmpTables, tmpDiskTables int
+}
+
+func expectResources(mock sqlmock.Sqlmock, c resourceCounters) {
+	mock.ExpectQuery(resourceStatusQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Open_files", strconv.Itoa(c.openFiles)).
+			AddRow("Open_tables", strconv.Itoa(c.openTables)).
+			AddRow("Opened_tables", strconv.Itoa(c.openedTables)).
+			AddRow("Threads_created", strconv.Itoa(c.threadsCreated)).
+			AddRow("Created_tmp_tables", strconv.Itoa(c.tmpTables)).
+			AddRow("Created_tmp_disk_tables", strconv.Itoa(c.tmpDiskTables)))
+	mock.ExpectQuery(resourceVariablesQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("open_files_limit", "1000").
+			AddRow("table_open_cache", "400"))
+}
+
+func TestResourceCheck(t *testing.T) {
+	thresholds := mariadb.ResourceThresholds{
+		OpenFilesPercent:        80,
+		OpenedTablesPerSecond:   10,
+		ThreadsCreatedPerSecond: 10,
+		TmpDiskTablesPercent:    25,
+	}
+
+	// Without a window the second check runs within milliseconds of the first,
+	// so any growth of a counter is a rate far above the thresholds.
+	tests := []struct {
+		name    string
+		first   resourceCounters
+		second  resourceCounters
+		status  health.Status
+		message string
+	}{
+		{
+			name:   "should be healthy when nothing grows",
+			first:  resourceCounters{openFiles: 100, openTables: 400, openedTables: 50},
+			second: resourceCounters{openFiles: 100, openTables: 400, openedTables: 50},
+			status: health.StatusHealthy,
+		},
+		{
+			name:    "should be degraded when open files near the limit",
+			first:   resourceCounters{openFiles: 100},
+			second:  resourceCounters{openFiles: 850},
+			status:  health.StatusDegraded,
+			message: "85% of open_files_limit in use (850/1000)",
+		},
+		{
+			name:   "should ignore opened tables while the table cache has room",
+			first:  resourceCounters{openTables: 100, openedTables: 50},
+			second: resourceCounters{openTables: 150, openedTables: 100},
+			status: health.StatusHealthy,
+		},
+		{
+			name:    "should be degraded when temporary tables spill to disk",
+			first:   resourceCounters{tmpTables: 10, tmpDiskTables: 1},
+			second:  resourceCounters{tmpTables: 20, tmpDiskTables: 6},
+			status:  health.StatusDegraded,
+			message: "50% of temporary tables created on disk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			check := mariadb.NewResourceCheck(thresholds)
+			check.Window = 0
+
+			expectResources(mock, tt.first)
+			expectResources(mock, tt.second)
+
+			check.Check(t.Context(), db)
+			result := check.Check(t.Context(), db)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, mariadb.CheckResources, result.Name)
+			assert.Equal(t, health.ScopeReadiness, result.Scope)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+
+	t.Run("should be degraded when the table cache thrashes and threads are created", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewResourceCheck(thresholds)
+		check.Window = 0
+
+		expectResources(mock, resourceCounters{openTables: 400, openedTables: 50, threadsCreated: 5})
+		expectResources(mock, resourceCounters{openTables: 400, openedTables: 500, threadsCreated: 500})
+
+		check.Check(t.Context(), db)
+		result := check.Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusDegraded, result.Status)
+		assert.Contains(t, result.Message, "table cache is full")
+		assert.Contains(t, result.Message, "thread cache too small")
+	})
+
+	t.Run("should not compare counters after a server restart", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewResourceCheck(thresholds)
+		check.Window = 0
+
+		expectResources(mock, resourceCounters{openTables: 400, openedTables: 5000, threadsCreated: 5000})
+		expectResources(mock, resourceCounters{openTables: 400, openedTables: 400, threadsCreated: 10})
+
+		check.Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusHealthy, check.Check(t.Context(), db).Status)
+	})
+
+	t.Run("should not compare counters within the window", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewResourceCheck(thresholds)
+
+		expectResources(mock, resourceCounters{tmpTables: 10})
+		expectResources(mock, resourceCounters{tmpTables: 11, tmpDiskTables: 1})
+
+		check.Check(t.Context(), db)
+		result := check.Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusHealthy, result.Status)
+		assert.NotContains(t, result.Details, "tmpDiskTablesPercent")
+	})
+
+	t.Run("should report the last comparison again within the window", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewResourceCheck(thresholds)
+		check.Window = 50 * time.Millisecond
+
+		expectResources(mock, resourceCounters{tmpTables: 10, tmpDiskTables: 1})
+		expectResources(mock, resourceCounters{tmpTables: 20, tmpDiskTables: 6})
+		expectResources(mock, resourceCounters{tmpTables: 20, tmpDiskTables: 6})
+
+		check.Check(t.Context(), db)
+		time.Sleep(check.Window)
+		check.Check(t.Context(), db)
+		result := check.Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusDegraded, result.Status)
+		assert.Equal(t, "50% of temporary tables created on disk", result.Message)
+	})
+
+	t.Run("should be unhealthy when a counter is missing", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(resourceStatusQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Open_files", "1"))
+		mock.ExpectQuery(resourceVariablesQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+
+		result := mariadb.NewResourceCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Contains(t, result.Message, "failed to read resource counters")
+	})
+}
+
+func TestResourceThresholdsValidate(t *testing.T) {
+	valid := mariadb.ResourceThresholds{
+		OpenFilesPercent:        80,
+		OpenedTablesPerSecond:   10,
+		ThreadsCreatedPerSecond: 10,
+		TmpDiskTablesPercent:    25,
+	}
+
+	t.Run("should accept valid thresholds", func(t *testing.T) {
+		assert.NoError(t, valid.Validate())
+	})
+
+	t.Run("should reject out of range thresholds", func(t *testing.T) {
+		invalid := valid
+		invalid.OpenFilesPercent = 120
+
+		assert.ErrorContains(t, invalid.Validate(), "invalid open files percent")
+
+		invalid = valid
+		invalid.ThreadsCreatedPerSecond = 0
+
+		assert.ErrorContains(t, invalid.Validate(), "invalid threads created rate")
+	})
+}
@@ -0,0 +1,191 @@
+package mariadb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// Role is the part a server currently plays in its topology.
+type Role string
+
+// Roles reported by DetectRole.
+const (
+	// RolePrimary is a writable server that does not replicate from another.
+	RolePrimary Role = "primary"
+	// RoleReplica is a server with a running replication channel, or with
+	// only stopped channels and read_only=ON.
+	RoleReplica Role = "replica"
+	// RoleReadOnly is a server with read_only=ON that does not replicate,
+	// e.g. a demoted primary that has not been repointed yet.
+	RoleReadOnly Role = "read-only"
+	// RoleGaleraSynced is a Galera node in sync with its cluster.
+	RoleGaleraSynced Role = "galera-synced"
+	// RoleGaleraDonor is a Galera node serving a state transfer.
+	RoleGaleraDonor Role = "galera-donor"
+	// RoleGaleraJoining is a Galera node receiving a state transfer.
+	RoleGaleraJoining Role = "galera-joining"
+	// RoleGaleraJoined is a Galera node catching up after a state transfer.
+	RoleGaleraJoined Role = "galera-joined"
+	// RoleGaleraNotReady is a Galera node outside the primary component or in
+	// any other state.
+	RoleGaleraNotReady Role = "galera-not-ready"
+)
+
+// Topology is what DetectRole derived the role from.
+type Topology struct {
+	Role                Role `json:"role"`
+	ReadOnly            bool `json:"readOnly"`
+	ReplicationChannels int  `json:"replicationChannels"`
+	// RunningChannels are the channels with the IO or the SQL thread not
+	// stopped.
+	RunningChannels     int    `json:"runningChannels"`
+	GaleraClusterStatus string `json:"galeraClusterStatus,omitempty"`
+	GaleraState         string `json:"galeraState,omitempty"`
+}
+
+// DetectRole derives the role of the server. Galera nodes are identified by
+// wsrep_on and reported by their local state. Otherwise a replication
+// channel with its IO or SQL thread running makes the server a replica. When
+// every channel has both threads stopped, read_only breaks the tie: a primary
+// promoted with STOP SLAVE and RESET SLAVE keeps its channel but is writable,
+// while a replica stopped for maintenance keeps read_only=ON. Without
+// channels, read_only tells a primary from a server that only accepts reads.
+//
+// Replication channels are listed with the syntax of the server's flavor.
+// Listing them requires the SLAVE MONITOR privilege (REPLICATION CLIENT
+// before MariaDB 10.5.9 and on MySQL).
+func DetectRole(ctx context.Context, db health.DB, server Server) (Topology, error) {
+	variables, err := GlobalVariables(ctx, db, "read_only", "wsrep_on")
+	if err != nil {
+		return Topology{}, err
+	}
+
+	topology := Topology{ReadOnly: isOn(variables["read_only"])}
+
+	if isOn(variables["wsrep_on"]) {
+		status, err := GlobalStatus(ctx, db, "wsrep_cluster_status", "wsrep_local_state_comment")
+		if err != nil {
+			return Topology{}, err
+		}
+
+		topology.GaleraClusterStatus = status["wsrep_cluster_status"]
+		topology.GaleraState = status["wsrep_local_state_comment"]
+		topology.Role = galeraRole(topology.GaleraClusterStatus, topology.GaleraState)
+
+		return topology, nil
+	}
+
+	channels, running, err := replicationChannels(ctx, db, server.ReplicaStatusQuery())
+	if err != nil {
+		return Topology{}, err
+	}
+
+	topology.ReplicationChannels = channels
+	topology.RunningChannels = running
+
+	switch {
+	case running > 0:
+		topology.Role = RoleReplica
+	case channels > 0 && topology.ReadOnly:
+		topology.Role = RoleReplica
+	case topology.ReadOnly:
+		topology.Role = RoleReadOnly
+	default:
+		topology.Role = RolePrimary
+	}
+
+	return topology, nil
+}
+
+// galeraRole maps wsrep_cluster_status and wsrep_local_state_comment to a
+// role. Only nodes in the primary component can be synced or donors.
+func galeraRole(clusterStatus, state string) Role {
+	if !strings.EqualFold(clusterStatus, "Primary") {
+		return RoleGaleraNotReady
+	}
+
+	switch {
+	case state == "Synced":
+		return RoleGaleraSynced
+	case strings.HasPrefix(state, "Donor"):
+		return RoleGaleraDonor
+	case state == "Joined":
+		return RoleGaleraJoined
+	case strings.HasPrefix(state, "Joining"), state == "Waiting on SST":
+		return RoleGaleraJoining
+	default:
+		return RoleGaleraNotReady
+	}
+}
+
+// replicationChannels returns the number of configured replication channels
+// and how many of them are running, i.e. do not report both the IO and the
+// SQL thread as stopped. A channel whose thread states are not reported
+// counts as running.
+func replicationChannels(ctx context.Context, db health.DB, query string) (int, int, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", query, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", query, err)
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dst := make([]any, len(columns))
+
+	for i := range values {
+		dst[i] = &values[i]
+	}
+
+	var channels, running int
+
+	for rows.Next() {
+		if err := rows.Scan(dst...); err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", query, err)
+		}
+
+		channels++
+
+		threads := map[string]string{}
+		for i, column := range columns {
+			threads[strings.ToLower(column)] = string(values[i])
+		}
+
+		if !stopped(threads) {
+			running++
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", query, err)
+	}
+
+	return channels, running, nil
+}
+
+// stopped reports whether a replica status row shows both the IO and the SQL
+// thread stopped, under the MariaDB or the MySQL 8.0.22 column names.
+func stopped(row map[string]string) bool {
+	for _, prefix := range []string{"slave", "replica"} {
+		io, hasIO := row[prefix+"_io_running"]
+		sqlThread, hasSQL := row[prefix+"_sql_running"]
+
+		if hasIO && hasSQL {
+			return strings.EqualFold(io, "No") && strings.EqualFold(sqlThread, "No")
+		}
+	}
+
+	return false
+}
+
+func isOn(value string) bool {
+	return strings.EqualFold(value, "ON") || value == "1"
+}
@@ -0,0 +1,143 @@
+package mariadb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	roleVariablesQuery = "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('read_only', 'wsrep_on')"
+	roleStatusQuery    = "SHOW GLOBAL STATUS WHERE Variable_name IN ('wsrep_cluster_status', 'wsrep_local_state_comment')"
+	replicasQuery      = "SHOW ALL SLAVES STATUS"
+)
+
+func TestDetectRole(t *testing.T) {
+	variables := func(readOnly, wsrepOn string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("read_only", readOnly).
+			AddRow("wsrep_on", wsrepOn)
+	}
+
+	t.Run("should detect primary, replica and read-only servers", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			readOnly string
+			channels int
+			running  string
+			want     mariadb.Role
+		}{
+			{name: "writable without channels", readOnly: "OFF", want: mariadb.RolePrimary},
+			{name: "running channel", readOnly: "ON", channels: 1, running: "Yes", want: mariadb.RoleReplica},
+			{name: "running channels, read_only=OFF", readOnly: "OFF", channels: 2, running: "Yes", want: mariadb.RoleReplica},
+			{name: "connecting channel", readOnly: "OFF", channels: 1, running: "Connecting", want: mariadb.RoleReplica},
+			{name: "read-only without channels", readOnly: "ON", want: mariadb.RoleReadOnly},
+			{name: "stopped channel, read_only=ON", readOnly: "ON", channels: 1, running: "No", want: mariadb.RoleReplica},
+			{name: "stopped channel, read_only=OFF", readOnly: "OFF", channels: 1, running: "No", want: mariadb.RolePrimary},
+		} {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+
+			replicas := sqlmock.NewRows([]string{"Connection_name", "Slave_IO_Running", "Slave_SQL_Running"})
+			for range tc.channels {
+				replicas.AddRow("", tc.running, tc.running)
+			}
+
+			mock.ExpectQuery(roleVariablesQuery).WillReturnRows(variables(tc.readOnly, "OFF"))
+			mock.ExpectQuery(replicasQuery).WillReturnRows(replicas)
+
+			topology, err := mariadb.DetectRole(t.Context(), db, mariadb.Server{})
+
+			require.NoError(t, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tc.want, topology.Role, tc.name)
+			assert.Equal(t, tc.channels, topology.ReplicationChannels, tc.name)
+
+			db.Close()
+		}
+	})
+
+	t.Run("should detect galera states", func(t *testing.T) {
+		for _, tc := range []struct {
+			clusterStatus string
+			state         string
+			want          mariadb.Role
+		}{
+			{clusterStatus: "Primary", state: "Synced", want: mariadb.RoleGaleraSynced},
+			{clusterStatus: "Primary", state: "Donor/Desynced", want: mariadb.RoleGaleraDonor},
+			{clusterStatus: "Primary", state: "Joining: receiving State Transfer", want: mariadb.RoleGaleraJoining},
+			{clusterStatus: "Primary", state: "Joined", want: mariadb.RoleGaleraJoined},
+			{clusterStatus: "non-Primary", state: "Synced", want: mariadb.RoleGaleraNotReady},
+			{clusterStatus: "Primary", state: "Initialized", want: mariadb.RoleGaleraNotReady},
+		} {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+
+			mock.ExpectQuery(roleVariablesQuery).WillReturnRows(variables("OFF", "ON"))
+			mock.ExpectQuery(roleStatusQuery).WillReturnRows(
+				sqlmock.NewRows([]string{"Variable_name", "Value"}).
+					AddRow("wsrep_cluster_status", tc.clusterStatus).
+					AddRow("wsrep_local_state_comment", tc.state))
+
+			topology, err := mariadb.DetectRole(t.Context(), db, mariadb.Server{})
+
+			require.NoError(t, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, tc.want, topology.Role, tc.state)
+			assert.Equal(t, tc.state, topology.GaleraState)
+
+			db.Close()
+		}
+	})
+
+	t.Run("should return error when replication status cannot be read", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(roleVariablesQuery).WillReturnRows(variables("OFF", "OFF"))
+		mock.ExpectQuery(replicasQuery).WillReturnError(errors.New("access denied"))
+
+		_, err = mariadb.DetectRole(t.Context(), db, mariadb.Server{})
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "SHOW ALL SLAVES STATUS")
+	})
+
+	t.Run("should list channels with the syntax of the flavor", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(roleVariablesQuery).WillReturnRows(variables("ON", ""))
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows([]string{"Replica_IO_State"}).AddRow("Waiting for source"))
+
+		topology, err := mariadb.DetectRole(t.Context(), db, mariadb.Server{Version: "8.0.36", Flavor: mariadb.FlavorMySQL})
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, mariadb.RoleReplica, topology.Role)
+	})
+	t.Run("should read the thread states under the MySQL column names", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(roleVariablesQuery).WillReturnRows(variables("OFF", ""))
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows([]string{"Replica_IO_Running", "Replica_SQL_Running"}).AddRow("No", "No"))
+
+		topology, err := mariadb.DetectRole(t.Context(), db, mariadb.Server{Version: "8.0.36", Flavor: mariadb.FlavorMySQL})
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, mariadb.RolePrimary, topology.Role)
+		assert.Equal(t, 1, topology.ReplicationChannels)
+		assert.Equal(t, 0, topology.RunningChannels)
+	})
+}
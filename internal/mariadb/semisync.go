@@ -0,0 +1,169 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// CheckSemiSync is the name of the semi-synchronous replication check.
+const CheckSemiSync = "semi_sync"
+
+// SemiSyncCheck watches a primary running semi-synchronous replication. Such
+// a primary still passes the round-trip after silently falling back to
+// asynchronous replication, although the durability guarantee is gone, so the
+// check reports degraded instead. Servers with semi-sync disabled, e.g.
+// replicas, are healthy; servers without the semi-sync plugin loaded are
+// degraded, as the check cannot tell what they do.
+type SemiSyncCheck struct {
+	// MinClients is the number of semi-sync replicas the primary needs.
+	MinClients uint64
+	// Window is the minimum time unacknowledged transactions are counted
+	// over. Checks within the window of the last count report it again.
+	Window time.Duration
+	// Server returns the server the check talks to, which picks the names
+	// of the semi-sync variables. Nil stands for the zero Server.
+	Server func(ctx context.Context, db health.DB) (Server, error)
+
+	unacknowledged counters
+}
+
+// NewSemiSyncCheck returns a semi-synchronous replication check.
+func NewSemiSyncCheck(minClients uint64) *SemiSyncCheck {
+	return &SemiSyncCheck{MinClients: minClients, Window: DefaultCounterWindow}
+}
+
+// Name implements health.Checker.
+func (c *SemiSyncCheck) Name() string {
+	return CheckSemiSync
+}
+
+// Check implements health.Checker.
+func (c *SemiSyncCheck) Check(ctx context.Context, db health.DB) health.Result {
+	start := time.Now()
+	result := c.check(ctx, db)
+	result.Name = CheckSemiSync
+	result.Scope = health.ScopeReadiness
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// semiSyncPrefix returns the prefix of the semi-sync status counters. MySQL
+// and Percona renamed them, and the matching variables, from
+// Rpl_semi_sync_master to Rpl_semi_sync_source in 8.0.26.
+func semiSyncPrefix(server Server) string {
+	if (server.Flavor == FlavorMySQL || server.Flavor == FlavorPercona) &&
+		server.AtLeast(Version{Major: 8, Minor: 0, Patch: 26}) {
+		return "Rpl_semi_sync_source"
+	}
+
+	return "Rpl_semi_sync_master"
+}
+
+func (c *SemiSyncCheck) check(ctx context.Context, db health.DB) health.Result {
+	var server Server
+
+	if c.Server != nil {
+		var err error
+
+		server, err = c.Server(ctx, db)
+		if err != nil {
+			return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+		}
+	}
+
+	prefix := semiSyncPrefix(server)
+	enabledName := strings.ToLower(prefix) + "_enabled"
+
+	variables, err := GlobalVariables(ctx, db, enabledName)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	enabled, ok := variables[enabledName]
+	if !ok {
+		return health.Result{
+			Status:  health.StatusDegraded,
+			Message: "semi-sync plugin not loaded",
+			Details: map[string]any{"variable": enabledName},
+		}
+	}
+
+	if !isOn(enabled) {
+		return health.Result{
+			Status:  health.StatusHealthy,
+			Details: map[string]any{"enabled": false},
+		}
+	}
+
+	var (
+		statusName  = prefix + "_status"
+		clientsName = prefix + "_clients"
+		noTxName    = prefix + "_no_tx"
+		yesTxName   = prefix + "_yes_tx"
+	)
+
+	status, err := GlobalStatus(ctx, db, statusName, clientsName, noTxName, yesTxName)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	var (
+		clients, noTx, yesTx uint64
+		errs                 []error
+	)
+
+	errs = Uints(status, map[string]*uint64{
+		clientsName: &clients,
+		noTxName:    &noTx,
+		yesTxName:   &yesTx,
+	})
+
+	active, ok := status[strings.ToLower(statusName)]
+	if !ok {
+		errs = append(errs, fmt.Errorf("%s is not reported by the server", statusName))
+	}
+
+	if len(errs) > 0 {
+		return health.Result{
+			Status:  health.StatusUnhealthy,
+			Message: fmt.Sprintf("failed to read semi-sync counters: %v", errs),
+		}
+	}
+
+	grown, elapsed, _ := c.unacknowledged.since(map[string]uint64{noTxName: noTx}, c.Window)
+	delta := grown[noTxName]
+
+	result := health.Result{
+		Status: health.StatusHealthy,
+		Details: map[string]any{
+			"enabled":                true,
+			"active":                 isOn(active),
+			"clients":                clients,
+			"minClients":             c.MinClients,
+			"noTx":                   noTx,
+			"yesTx":                  yesTx,
+			"unacknowledgedInWindow": delta,
+			"windowSeconds":          elapsed.Seconds(),
+		},
+	}
+
+	switch {
+	case !isOn(active):
+		result.Status = health.StatusDegraded
+		result.Message = "semi-sync is enabled but the primary fell back to asynchronous replication"
+	case clients < c.MinClients:
+		result.Status = health.StatusDegraded
+		result.Message = fmt.Sprintf("%d semi-sync replicas connected, at least %d required", clients, c.MinClients)
+	case delta > 0:
+		result.Status = health.StatusDegraded
+		result.Message = fmt.Sprintf("%d transactions committed without semi-sync acknowledgement in %s",
+			delta, lastWindow(elapsed))
+	}
+
+	return result
+}
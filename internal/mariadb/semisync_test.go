@@ -0,0 +1,167 @@
+package mariadb_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	semiSyncVariablesQuery = "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('rpl_semi_sync_master_enabled')"
+	semiSyncStatusQuery    = "SHOW GLOBAL STATUS WHERE Variable_name IN ('Rpl_semi_sync_master_status', " +
+		"'Rpl_semi_sync_master_clients', 'Rpl_semi_sync_master_no_tx', 'Rpl_semi_sync_master_yes_tx')"
+)
+
+func expectSemiSync(mock sqlmock.Sqlmock, active string, clients, noTx int) {
+	mock.ExpectQuery(semiSyncVariablesQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("rpl_semi_sync_master_enabled", "ON"))
+	mock.ExpectQuery(semiSyncStatusQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Rpl_semi_sync_master_status", active).
+			AddRow("Rpl_semi_sync_master_clients", strconv.Itoa(clients)).
+			AddRow("Rpl_semi_sync_master_no_tx", strconv.Itoa(noTx)).
+			AddRow("Rpl_semi_sync_master_yes_tx", "100"))
+}
+
+func TestSemiSyncCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		active  string
+		clients int
+		status  health.Status
+		message string
+	}{
+		{name: "should be healthy with enough replicas", active: "ON", clients: 2, status: health.StatusHealthy},
+		{
+			name: "should be degraded after falling back to async", active: "OFF", clients: 2,
+			status: health.StatusDegraded, message: "semi-sync is enabled but the primary fell back to asynchronous replication",
+		},
+		{
+			name: "should be degraded with too few replicas", active: "ON", clients: 1,
+			status: health.StatusDegraded, message: "1 semi-sync replicas connected, at least 2 required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			expectSemiSync(mock, tt.active, tt.clients, 0)
+
+			result := mariadb.NewSemiSyncCheck(2).Check(t.Context(), db)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, mariadb.CheckSemiSync, result.Name)
+			assert.Equal(t, health.ScopeReadiness, result.Scope)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+
+	t.Run("should be healthy when semi-sync is disabled", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(semiSyncVariablesQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("rpl_semi_sync_master_enabled", "OFF"))
+
+		result := mariadb.NewSemiSyncCheck(1).Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, health.StatusHealthy, result.Status)
+		assert.Equal(t, false, result.Details["enabled"])
+	})
+
+	t.Run("should be degraded when transactions went unacknowledged in the window", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewSemiSyncCheck(1)
+		check.Window = 0
+
+		expectSemiSync(mock, "ON", 1, 5)
+		expectSemiSync(mock, "ON", 1, 8)
+
+		assert.Equal(t, health.StatusHealthy, check.Check(t.Context(), db).Status)
+
+		result := check.Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, health.StatusDegraded, result.Status)
+		assert.Equal(t, "3 transactions committed without semi-sync acknowledgement in the last 1s", result.Message)
+		assert.Equal(t, uint64(3), result.Details["unacknowledgedInWindow"])
+	})
+
+	t.Run("should be degraded when the plugin is not loaded", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(semiSyncVariablesQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+
+		result := mariadb.NewSemiSyncCheck(1).Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, health.StatusDegraded, result.Status)
+		assert.Equal(t, "semi-sync plugin not loaded", result.Message)
+	})
+
+	t.Run("should read the source variables on MySQL 8.0.26", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL VARIABLES WHERE Variable_name IN ('rpl_semi_sync_source_enabled')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("rpl_semi_sync_source_enabled", "ON"))
+		mock.ExpectQuery("SHOW GLOBAL STATUS WHERE Variable_name IN ('Rpl_semi_sync_source_status', " +
+			"'Rpl_semi_sync_source_clients', 'Rpl_semi_sync_source_no_tx', 'Rpl_semi_sync_source_yes_tx')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Rpl_semi_sync_source_status", "ON").
+				AddRow("Rpl_semi_sync_source_clients", "1").
+				AddRow("Rpl_semi_sync_source_no_tx", "0").
+				AddRow("Rpl_semi_sync_source_yes_tx", "100"))
+
+		check := mariadb.NewSemiSyncCheck(1)
+		check.Server = func(context.Context, health.DB) (mariadb.Server, error) {
+			return mariadb.Server{Version: "8.0.36", Flavor: mariadb.FlavorMySQL}, nil
+		}
+
+		result := check.Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, health.StatusHealthy, result.Status)
+		assert.Equal(t, uint64(1), result.Details["clients"])
+	})
+
+	t.Run("should be unhealthy when a counter is missing", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(semiSyncVariablesQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("rpl_semi_sync_master_enabled", "ON"))
+		mock.ExpectQuery(semiSyncStatusQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Rpl_semi_sync_master_status", "ON"))
+
+		result := mariadb.NewSemiSyncCheck(1).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Contains(t, result.Message, "failed to read semi-sync counters")
+	})
+}
@@ -0,0 +1,136 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// Flavor is the server implementation behind the MySQL protocol.
+type Flavor string
+
+// Flavors reported by DetectServer.
+const (
+	FlavorMariaDB Flavor = "mariadb"
+	FlavorMySQL   Flavor = "mysql"
+	FlavorPercona Flavor = "percona"
+)
+
+// versionNumber matches the leading major.minor[.patch] of VERSION().
+var versionNumber = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Version is a server version number.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses the leading number of a version such as
+// "10.11.6-MariaDB-log", "8.0.36" or "10.6".
+func ParseVersion(value string) (Version, error) {
+	match := versionNumber.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid version %q", value)
+	}
+
+	var v Version
+
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+
+	if match[3] != "" {
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+
+	return v, nil
+}
+
+// Less reports whether v is older than other.
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+
+	return v.Patch < other.Patch
+}
+
+// String returns the version as major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Server identifies the server the sidecar talks to. The zero value stands
+// for an undetected server and picks the MariaDB syntax.
+type Server struct {
+	Version        string `json:"version"`
+	VersionComment string `json:"versionComment"`
+	ServerID       uint64 `json:"serverId"`
+	Hostname       string `json:"hostname"`
+	Flavor         Flavor `json:"flavor"`
+}
+
+// DetectServer reads the version and identity of the server.
+func DetectServer(ctx context.Context, db health.DB) (Server, error) {
+	var server Server
+
+	err := db.QueryRowContext(ctx, "SELECT VERSION(), @@version_comment, @@server_id, @@hostname").
+		Scan(&server.Version, &server.VersionComment, &server.ServerID, &server.Hostname)
+	if err != nil {
+		return Server{}, fmt.Errorf("DetectServer: %w", err)
+	}
+
+	server.Flavor = detectFlavor(server.Version, server.VersionComment)
+
+	return server, nil
+}
+
+// detectFlavor tells the flavors apart. MariaDB says so in VERSION(); Percona
+// only in @@version_comment, e.g. "Percona Server (GPL), Release 27".
+func detectFlavor(version, comment string) Flavor {
+	switch {
+	case strings.Contains(strings.ToLower(version), "mariadb"):
+		return FlavorMariaDB
+	case strings.Contains(strings.ToLower(comment), "percona"):
+		return FlavorPercona
+	default:
+		return FlavorMySQL
+	}
+}
+
+// Number returns the parsed version number; the zero Version when it cannot
+// be parsed.
+func (s Server) Number() Version {
+	v, _ := ParseVersion(s.Version)
+
+	return v
+}
+
+// AtLeast reports whether the server version is minimum or newer.
+func (s Server) AtLeast(minimum Version) bool {
+	return !s.Number().Less(minimum)
+}
+
+// ReplicaStatusQuery returns the statement listing replication channels.
+// MariaDB lists every source with SHOW ALL SLAVES STATUS; MySQL and Percona
+// renamed SHOW SLAVE STATUS in 8.0.22.
+func (s Server) ReplicaStatusQuery() string {
+	switch {
+	case s.Flavor == FlavorMySQL || s.Flavor == FlavorPercona:
+		if s.AtLeast(Version{Major: 8, Minor: 0, Patch: 22}) {
+			return "SHOW REPLICA STATUS"
+		}
+
+		return "SHOW SLAVE STATUS"
+	default:
+		return "SHOW ALL SLAVES STATUS"
+	}
+}
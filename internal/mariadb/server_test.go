@@ -0,0 +1,88 @@
+package mariadb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverQuery = "SELECT VERSION(), @@version_comment, @@server_id, @@hostname"
+
+func TestParseVersion(t *testing.T) {
+	for value, want := range map[string]mariadb.Version{
+		"10.11.6-MariaDB-1:10.11.6+maria~ubu2204-log": {Major: 10, Minor: 11, Patch: 6},
+		"8.0.36":    {Major: 8, Minor: 0, Patch: 36},
+		"8.0.35-27": {Major: 8, Minor: 0, Patch: 35},
+		"10.6":      {Major: 10, Minor: 6},
+	} {
+		t.Run("should parse "+value, func(t *testing.T) {
+			version, err := mariadb.ParseVersion(value)
+
+			require.NoError(t, err)
+			assert.Equal(t, want, version)
+		})
+	}
+
+	t.Run("should reject a value without a version number", func(t *testing.T) {
+		_, err := mariadb.ParseVersion("latest")
+
+		assert.ErrorContains(t, err, `invalid version "latest"`)
+	})
+}
+
+func TestDetectServer(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		comment string
+		flavor  mariadb.Flavor
+		replica string
+	}{
+		{"11.4.2-MariaDB-ubu2404", "mariadb.org binary distribution", mariadb.FlavorMariaDB, "SHOW ALL SLAVES STATUS"},
+		{"8.0.36", "MySQL Community Server - GPL", mariadb.FlavorMySQL, "SHOW REPLICA STATUS"},
+		{"5.7.44-log", "MySQL Community Server (GPL)", mariadb.FlavorMySQL, "SHOW SLAVE STATUS"},
+		{"8.0.35-27", "Percona Server (GPL), Release 27, Revision 2f8eeab2", mariadb.FlavorPercona, "SHOW REPLICA STATUS"},
+	} {
+		t.Run("should detect "+tc.comment, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(serverQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"VERSION()", "@@version_comment", "@@server_id", "@@hostname"}).
+					AddRow(tc.version, tc.comment, 7, "mariadb-0"))
+
+			server, err := mariadb.DetectServer(t.Context(), db)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.flavor, server.Flavor)
+			assert.Equal(t, uint64(7), server.ServerID)
+			assert.Equal(t, "mariadb-0", server.Hostname)
+			assert.Equal(t, tc.replica, server.ReplicaStatusQuery())
+		})
+	}
+
+	t.Run("should return error when the query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(serverQuery).WillReturnError(errors.New("gone"))
+
+		_, err = mariadb.DetectServer(t.Context(), db)
+
+		assert.ErrorContains(t, err, "DetectServer")
+	})
+}
+
+func TestServerAtLeast(t *testing.T) {
+	server := mariadb.Server{Version: "10.11.6-MariaDB"}
+
+	assert.True(t, server.AtLeast(mariadb.Version{Major: 10, Minor: 6}))
+	assert.True(t, server.AtLeast(mariadb.Version{Major: 10, Minor: 11, Patch: 6}))
+	assert.False(t, server.AtLeast(mariadb.Version{Major: 10, Minor: 11, Patch: 7}))
+	assert.False(t, server.AtLeast(mariadb.Version{Major: 11}))
+}
@@ -0,0 +1,105 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// variableName matches the names of server status and system variables.
+// Names are inlined into SHOW statements, so anything else is rejected.
+var variableName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// GlobalStatus returns the named SHOW GLOBAL STATUS values keyed by their
+// lower-cased name. Missing variables are absent from the map.
+func GlobalStatus(ctx context.Context, db health.DB, names ...string) (map[string]string, error) {
+	return show(ctx, db, "SHOW GLOBAL STATUS", names)
+}
+
+// GlobalVariables returns the named SHOW GLOBAL VARIABLES values keyed by
+// their lower-cased name. Missing variables are absent from the map.
+func GlobalVariables(ctx context.Context, db health.DB, names ...string) (map[string]string, error) {
+	return show(ctx, db, "SHOW GLOBAL VARIABLES", names)
+}
+
+// IsVariableName reports whether name can be passed to GlobalStatus and
+// GlobalVariables.
+func IsVariableName(name string) bool {
+	return variableName.MatchString(name)
+}
+
+func show(ctx context.Context, db health.DB, statement string, names []string) (map[string]string, error) {
+	quoted := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if !variableName.MatchString(name) {
+			return nil, fmt.Errorf("%s: invalid variable name %q", statement, name)
+		}
+
+		quoted = append(quoted, "'"+name+"'")
+	}
+
+	query := statement
+	if len(quoted) > 0 {
+		query += " WHERE Variable_name IN (" + strings.Join(quoted, ", ") + ")"
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", statement, err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string, len(names))
+
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("%s: %w", statement, err)
+		}
+
+		values[strings.ToLower(name)] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", statement, err)
+	}
+
+	return values, nil
+}
+
+// Uint parses the named value as an unsigned integer.
+func Uint(values map[string]string, name string) (uint64, error) {
+	raw, ok := values[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("%s is not reported by the server", name)
+	}
+
+	n, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	return n, nil
+}
+
+// Uints parses the named values into their destinations as unsigned
+// integers and returns an error for every value missing or invalid.
+func Uints(values map[string]string, dst map[string]*uint64) []error {
+	var errs []error
+
+	for name, d := range dst {
+		n, err := Uint(values, name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		*d = n
+	}
+
+	return errs
+}
@@ -0,0 +1,97 @@
+package mariadb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalStatus(t *testing.T) {
+	t.Run("should return values keyed by lower-cased name", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL STATUS WHERE Variable_name IN ('Threads_connected', 'Uptime')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Threads_connected", "7").
+				AddRow("Uptime", "42"))
+
+		values, err := mariadb.GlobalStatus(t.Context(), db, "Threads_connected", "Uptime")
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, map[string]string{"threads_connected": "7", "uptime": "42"}, values)
+	})
+
+	t.Run("should return error for invalid variable name", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		_, err = mariadb.GlobalVariables(t.Context(), db, "x'; DROP TABLE status; --")
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid variable name")
+	})
+
+	t.Run("should return error when the query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL VARIABLES WHERE Variable_name IN ('max_connections')").
+			WillReturnError(errors.New("boom"))
+
+		_, err = mariadb.GlobalVariables(t.Context(), db, "max_connections")
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "SHOW GLOBAL VARIABLES")
+	})
+}
+
+func TestUint(t *testing.T) {
+	t.Run("should parse value case-insensitively", func(t *testing.T) {
+		n, err := mariadb.Uint(map[string]string{"max_connections": "151"}, "MAX_CONNECTIONS")
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(151), n)
+	})
+
+	t.Run("should return error for missing value", func(t *testing.T) {
+		_, err := mariadb.Uint(map[string]string{}, "max_connections")
+
+		assert.ErrorContains(t, err, "not reported")
+	})
+
+	t.Run("should return error for non-numeric value", func(t *testing.T) {
+		_, err := mariadb.Uint(map[string]string{"x": "ON"}, "x")
+
+		assert.ErrorContains(t, err, "invalid value")
+	})
+}
+
+func TestUints(t *testing.T) {
+	t.Run("should parse every value", func(t *testing.T) {
+		var a, b uint64
+
+		errs := mariadb.Uints(map[string]string{"a": "1", "b": "2"}, map[string]*uint64{"A": &a, "B": &b})
+
+		assert.Empty(t, errs)
+		assert.Equal(t, uint64(1), a)
+		assert.Equal(t, uint64(2), b)
+	})
+
+	t.Run("should return an error per missing or invalid value", func(t *testing.T) {
+		var a, b, c uint64
+
+		errs := mariadb.Uints(map[string]string{"a": "1", "b": "x"}, map[string]*uint64{"A": &a, "B": &b, "C": &c})
+
+		assert.Len(t, errs, 2)
+		assert.Equal(t, uint64(1), a)
+	})
+}
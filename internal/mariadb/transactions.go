@@ -0,0 +1,183 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// CheckTransactions is the name of the long-running transaction and metadata
+// lock check.
+const CheckTransactions = "transactions"
+
+const (
+	// maxReportedThreads caps the offenders listed in the details.
+	maxReportedThreads = 10
+	// maxQueryText caps the query text of an offender.
+	maxQueryText = 128
+)
+
+const (
+	longTransactionsQuery = "SELECT t.trx_mysql_thread_id, COALESCE(p.USER, ''), " +
+		"TIMESTAMPDIFF(SECOND, t.trx_started, NOW()), COALESCE(t.trx_query, p.INFO, '') " +
+		"FROM information_schema.INNODB_TRX t " +
+		"LEFT JOIN information_schema.PROCESSLIST p ON p.ID = t.trx_mysql_thread_id " +
+		"WHERE t.trx_started <= NOW() - INTERVAL ? SECOND ORDER BY t.trx_started"
+	metadataLockWaitsQuery = "SELECT ID, USER, TIME, COALESCE(INFO, '') " +
+		"FROM information_schema.PROCESSLIST " +
+		"WHERE STATE = 'Waiting for table metadata lock' AND TIME >= ? ORDER BY TIME DESC"
+)
+
+// TransactionThresholds are the ages at which the transactions check turns
+// degraded.
+type TransactionThresholds struct {
+	// MaxAge is the age of an open InnoDB transaction.
+	MaxAge time.Duration
+	// MaxLockWait is how long a thread waits for a table metadata lock.
+	MaxLockWait time.Duration
+}
+
+// Validate validates the thresholds. Both are compared in whole seconds, the
+// resolution of the server.
+func (t TransactionThresholds) Validate() error {
+	if t.MaxAge < time.Second {
+		return fmt.Errorf("invalid max age: %s", t.MaxAge)
+	}
+
+	if t.MaxLockWait < time.Second {
+		return fmt.Errorf("invalid max lock wait: %s", t.MaxLockWait)
+	}
+
+	return nil
+}
+
+// Thread is a server thread reported by the transactions check.
+type Thread struct {
+	ID      uint64 `json:"id"`
+	User    string `json:"user"`
+	Seconds uint64 `json:"seconds"`
+	Query   string `json:"query,omitempty"`
+}
+
+// TransactionCheck catches forgotten transactions, which block purge and hold
+// row locks, and metadata lock pileups, where a DDL statement waits behind a
+// long transaction and every later query on the table queues behind it. Both
+// build up long before the round-trip gets stuck. Both only degrade
+// readiness; it fails readiness when the tables cannot be read. The offenders
+// are listed in the verbose output.
+type TransactionCheck struct {
+	Thresholds TransactionThresholds
+	// ShowQueries lists the query text of the offenders. The verbose output
+	// is served without authentication, so other sessions' SQL is left out
+	// unless set.
+	ShowQueries bool
+}
+
+// NewTransactionCheck returns a long-running transaction and metadata lock
+// check.
+func NewTransactionCheck(thresholds TransactionThresholds) *TransactionCheck {
+	return &TransactionCheck{Thresholds: thresholds}
+}
+
+// Name implements health.Checker.
+func (c *TransactionCheck) Name() string {
+	return CheckTransactions
+}
+
+// Check implements health.Checker.
+func (c *TransactionCheck) Check(ctx context.Context, db health.DB) health.Result {
+	start := time.Now()
+	result := c.check(ctx, db)
+	result.Name = CheckTransactions
+	result.Scope = health.ScopeReadiness
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+func (c *TransactionCheck) check(ctx context.Context, db health.DB) health.Result {
+	transactions, err := threads(ctx, db, longTransactionsQuery, c.Thresholds.MaxAge, c.ShowQueries)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	waits, err := threads(ctx, db, metadataLockWaitsQuery, c.Thresholds.MaxLockWait, c.ShowQueries)
+	if err != nil {
+		return health.Result{Status: health.StatusUnhealthy, Message: err.Error()}
+	}
+
+	details := map[string]any{
+		"longTransactions":  len(transactions),
+		"metadataLockWaits": len(waits),
+	}
+
+	var problems []string
+
+	if len(transactions) > 0 {
+		details["transactions"] = transactions[:min(len(transactions), maxReportedThreads)]
+		problems = append(problems, fmt.Sprintf("%d transactions open for more than %s (oldest %ds, thread %d)",
+			len(transactions), c.Thresholds.MaxAge, transactions[0].Seconds, transactions[0].ID))
+	}
+
+	if len(waits) > 0 {
+		details["lockWaits"] = waits[:min(len(waits), maxReportedThreads)]
+		problems = append(problems, fmt.Sprintf("%d threads waiting for a metadata lock for more than %s (longest %ds, thread %d)",
+			len(waits), c.Thresholds.MaxLockWait, waits[0].Seconds, waits[0].ID))
+	}
+
+	result := health.Result{Status: health.StatusHealthy, Details: details}
+
+	if len(problems) > 0 {
+		result.Status = health.StatusDegraded
+		result.Message = strings.Join(problems, "; ")
+	}
+
+	return result
+}
+
+// threads runs query, which selects the id, user, age in seconds and query
+// text of the threads at least threshold old. The query text is dropped
+// unless withQuery is set.
+func threads(ctx context.Context, db health.DB, query string, threshold time.Duration, withQuery bool) ([]Thread, error) {
+	rows, err := db.QueryContext(ctx, query, int64(threshold/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", query, err)
+	}
+	defer rows.Close()
+
+	var found []Thread
+
+	for rows.Next() {
+		var thread Thread
+
+		if err := rows.Scan(&thread.ID, &thread.User, &thread.Seconds, &thread.Query); err != nil {
+			return nil, fmt.Errorf("%s: %w", query, err)
+		}
+
+		thread.Query = truncate(strings.Join(strings.Fields(thread.Query), " "), maxQueryText)
+		if !withQuery {
+			thread.Query = ""
+		}
+
+		found = append(found, thread)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", query, err)
+	}
+
+	return found, nil
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,121 @@
+package mariadb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	longTransactionsQuery = "SELECT t.trx_mysql_thread_id, COALESCE(p.USER, ''), " +
+		"TIMESTAMPDIFF(SECOND, t.trx_started, NOW()), COALESCE(t.trx_query, p.INFO, '') " +
+		"FROM information_schema.INNODB_TRX t " +
+		"LEFT JOIN information_schema.PROCESSLIST p ON p.ID = t.trx_mysql_thread_id " +
+		"WHERE t.trx_started <= NOW() - INTERVAL ? SECOND ORDER BY t.trx_started"
+	metadataLockWaitsQuery = "SELECT ID, USER, TIME, COALESCE(INFO, '') " +
+		"FROM information_schema.PROCESSLIST " +
+		"WHERE STATE = 'Waiting for table metadata lock' AND TIME >= ? ORDER BY TIME DESC"
+)
+
+var threadColumns = []string{"ID", "USER", "TIME", "INFO"}
+
+func TestTransactionCheck(t *testing.T) {
+	thresholds := mariadb.TransactionThresholds{MaxAge: time.Minute, MaxLockWait: 30 * time.Second}
+
+	t.Run("should be healthy without long transactions or lock waits", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(longTransactionsQuery).WithArgs(60).WillReturnRows(sqlmock.NewRows(threadColumns))
+		mock.ExpectQuery(metadataLockWaitsQuery).WithArgs(30).WillReturnRows(sqlmock.NewRows(threadColumns))
+
+		result := mariadb.NewTransactionCheck(thresholds).Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, mariadb.CheckTransactions, result.Name)
+		assert.Equal(t, health.ScopeReadiness, result.Scope)
+		assert.Equal(t, health.StatusHealthy, result.Status)
+		assert.Empty(t, result.Message)
+	})
+
+	t.Run("should be degraded and list the offenders", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		check := mariadb.NewTransactionCheck(thresholds)
+		check.ShowQueries = true
+
+		mock.ExpectQuery(longTransactionsQuery).WithArgs(60).
+			WillReturnRows(sqlmock.NewRows(threadColumns).
+				AddRow(12, "app", 3600, "").
+				AddRow(15, "batch", 90, "UPDATE orders\n   SET state = 'done'"))
+		mock.ExpectQuery(metadataLockWaitsQuery).WithArgs(30).
+			WillReturnRows(sqlmock.NewRows(threadColumns).
+				AddRow(20, "migrate", 45, "ALTER TABLE orders ADD COLUMN note TEXT "+strings.Repeat("x", 200)))
+
+		result := check.Check(t.Context(), db)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, health.StatusDegraded, result.Status)
+		assert.Equal(t, "2 transactions open for more than 1m0s (oldest 3600s, thread 12); "+
+			"1 threads waiting for a metadata lock for more than 30s (longest 45s, thread 20)", result.Message)
+		assert.Equal(t, []mariadb.Thread{
+			{ID: 12, User: "app", Seconds: 3600},
+			{ID: 15, User: "batch", Seconds: 90, Query: "UPDATE orders SET state = 'done'"},
+		}, result.Details["transactions"])
+
+		waits := result.Details["lockWaits"].([]mariadb.Thread)
+		require.Len(t, waits, 1)
+		assert.Len(t, []rune(waits[0].Query), 128)
+		assert.True(t, strings.HasSuffix(waits[0].Query, "…"))
+	})
+
+	t.Run("should leave the query text out by default", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(longTransactionsQuery).WithArgs(60).
+			WillReturnRows(sqlmock.NewRows(threadColumns).AddRow(15, "batch", 90, "UPDATE orders SET state = 'done'"))
+		mock.ExpectQuery(metadataLockWaitsQuery).WithArgs(30).WillReturnRows(sqlmock.NewRows(threadColumns))
+
+		result := mariadb.NewTransactionCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, []mariadb.Thread{{ID: 15, User: "batch", Seconds: 90}}, result.Details["transactions"])
+	})
+
+	t.Run("should be unhealthy without the PROCESS privilege", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(longTransactionsQuery).WithArgs(60).
+			WillReturnError(errors.New("Error 1227: Access denied; you need the PROCESS privilege"))
+
+		result := mariadb.NewTransactionCheck(thresholds).Check(t.Context(), db)
+
+		assert.Equal(t, health.StatusUnhealthy, result.Status)
+		assert.Equal(t, longTransactionsQuery+": Error 1227: Access denied; you need the PROCESS privilege", result.Message)
+	})
+}
+
+func TestTransactionThresholdsValidate(t *testing.T) {
+	t.Run("should accept valid thresholds", func(t *testing.T) {
+		assert.NoError(t, mariadb.TransactionThresholds{MaxAge: time.Minute, MaxLockWait: time.Second}.Validate())
+	})
+
+	t.Run("should reject sub-second thresholds", func(t *testing.T) {
+		err := mariadb.TransactionThresholds{MaxAge: time.Minute, MaxLockWait: time.Millisecond}.Validate()
+
+		assert.ErrorContains(t, err, "invalid max lock wait")
+	})
+}
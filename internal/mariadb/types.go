@@ -8,4 +8,6 @@ type Connection struct {
 	Password string `env:"DB_PASSWORD"`
 	Port     string `env:"DB_PORT"`
 	User     string `env:"DB_USER"`
+	// Pool sizes the connection pool; the zero value stands for DefaultPool.
+	Pool Pool
 }
@@ -0,0 +1,191 @@
+// Package metrics re-exports an allowlist of MariaDB server status and
+// system variables in the Prometheus text format. Metric names follow
+// mysqld_exporter, e.g. mysql_global_status_threads_connected, so existing
+// dashboards and alerts keep working.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultStatus is the SHOW GLOBAL STATUS allowlist used when none is
+// configured: queries, connections, InnoDB buffer pool, replication, wsrep and
+// aborted clients.
+var DefaultStatus = []string{
+	"Uptime",
+	"Queries",
+	"Questions",
+	"Slow_queries",
+	"Com_select",
+	"Com_insert",
+	"Com_update",
+	"Com_delete",
+	"Bytes_received",
+	"Bytes_sent",
+	"Connections",
+	"Threads_connected",
+	"Threads_running",
+	"Threads_created",
+	"Max_used_connections",
+	"Aborted_clients",
+	"Aborted_connects",
+	"Connection_errors_max_connections",
+	"Open_files",
+	"Opened_tables",
+	"Created_tmp_disk_tables",
+	"Innodb_buffer_pool_pages_total",
+	"Innodb_buffer_pool_pages_free",
+	"Innodb_buffer_pool_pages_dirty",
+	"Innodb_buffer_pool_read_requests",
+	"Innodb_buffer_pool_reads",
+	"Innodb_row_lock_waits",
+	"Innodb_row_lock_time",
+	"Innodb_deadlocks",
+	"Slave_running",
+	"Slaves_connected",
+	"Slaves_running",
+	"Rpl_semi_sync_master_status",
+	"Rpl_semi_sync_master_clients",
+	"wsrep_ready",
+	"wsrep_connected",
+	"wsrep_cluster_size",
+	"wsrep_cluster_status",
+	"wsrep_local_state",
+	"wsrep_local_recv_queue",
+	"wsrep_flow_control_paused",
+}
+
+// DefaultVariables is the SHOW GLOBAL VARIABLES allowlist used when none is
+// configured.
+var DefaultVariables = []string{
+	"max_connections",
+	"open_files_limit",
+	"table_open_cache",
+	"thread_cache_size",
+	"innodb_buffer_pool_size",
+	"read_only",
+}
+
+// Sample is a single metric value.
+type Sample struct {
+	Name string
+	Help string
+	// Type is the Prometheus metric type, "untyped" or "gauge".
+	Type  string
+	Value float64
+}
+
+// Exporter reads the allowlisted values from the server.
+type Exporter struct {
+	Status    []string
+	Variables []string
+}
+
+// Validate validates the allowlists.
+func (e Exporter) Validate() error {
+	for _, name := range slices.Concat(e.Status, e.Variables) {
+		if !mariadb.IsVariableName(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Collect reads the allowlisted values. Values the server does not report, or
+// that are neither numeric nor boolean, are skipped.
+func (e Exporter) Collect(ctx context.Context, db *sql.DB) ([]Sample, error) {
+	var samples []Sample
+
+	if len(e.Status) > 0 {
+		status, err := mariadb.GlobalStatus(ctx, db, e.Status...)
+		if err != nil {
+			return nil, err
+		}
+
+		samples = append(samples, convert(status, "mysql_global_status_", "Generic metric from SHOW GLOBAL STATUS.", "untyped")...)
+	}
+
+	if len(e.Variables) > 0 {
+		variables, err := mariadb.GlobalVariables(ctx, db, e.Variables...)
+		if err != nil {
+			return nil, err
+		}
+
+		samples = append(samples, convert(variables, "mysql_global_variables_", "Generic gauge metric from SHOW GLOBAL VARIABLES.", "gauge")...)
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Name < samples[j].Name })
+
+	return samples, nil
+}
+
+func convert(values map[string]string, prefix, help, kind string) []Sample {
+	samples := make([]Sample, 0, len(values))
+
+	for name, raw := range values {
+		value, ok := parseValue(raw)
+		if !ok {
+			continue
+		}
+
+		samples = append(samples, Sample{Name: prefix + name, Help: help, Type: kind, Value: value})
+	}
+
+	return samples
+}
+
+// parseValue converts a status or variable value to a number. Like
+// mysqld_exporter it maps ON/YES and Primary (wsrep_cluster_status) to 1.
+func parseValue(raw string) (float64, bool) {
+	if value, err := strconv.ParseFloat(raw, 64); err == nil {
+		return value, true
+	}
+
+	switch strings.ToLower(raw) {
+	case "on", "yes", "true", "primary":
+		return 1, true
+	case "off", "no", "false", "non-primary", "disconnected":
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// Up returns the mysql_up sample reporting whether the values could be read.
+func Up(up bool) Sample {
+	sample := Sample{Name: "mysql_up", Help: "Whether the MariaDB server could be queried.", Type: "gauge"}
+	if up {
+		sample.Value = 1
+	}
+
+	return sample
+}
+
+// Write writes samples in the Prometheus text format.
+func Write(w io.Writer, samples []Sample) error {
+	for _, sample := range samples {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n",
+			sample.Name, sample.Help,
+			sample.Name, sample.Type,
+			sample.Name, strconv.FormatFloat(sample.Value, 'g', -1, 64),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write metric %s: %w", sample.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/richie-tt/mariadb-healthcheck/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	exporter := metrics.Exporter{
+		Status:    []string{"Threads_connected", "wsrep_cluster_status", "wsrep_local_state_comment"},
+		Variables: []string{"read_only"},
+	}
+
+	t.Run("should convert numeric and boolean values", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL STATUS WHERE Variable_name IN " +
+			"('Threads_connected', 'wsrep_cluster_status', 'wsrep_local_state_comment')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Threads_connected", "7").
+				AddRow("wsrep_cluster_status", "Primary").
+				AddRow("wsrep_local_state_comment", "Synced"))
+		mock.ExpectQuery("SHOW GLOBAL VARIABLES WHERE Variable_name IN ('read_only')").
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("read_only", "OFF"))
+
+		samples, err := exporter.Collect(t.Context(), db)
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		var out bytes.Buffer
+		require.NoError(t, metrics.Write(&out, samples))
+
+		assert.Equal(t, `# HELP mysql_global_status_threads_connected Generic metric from SHOW GLOBAL STATUS.
+# TYPE mysql_global_status_threads_connected untyped
+mysql_global_status_threads_connected 7
+# HELP mysql_global_status_wsrep_cluster_status Generic metric from SHOW GLOBAL STATUS.
+# TYPE mysql_global_status_wsrep_cluster_status untyped
+mysql_global_status_wsrep_cluster_status 1
+# HELP mysql_global_variables_read_only Generic gauge metric from SHOW GLOBAL VARIABLES.
+# TYPE mysql_global_variables_read_only gauge
+mysql_global_variables_read_only 0
+`, out.String())
+	})
+
+	t.Run("should return error when the query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW GLOBAL STATUS").WillReturnError(errors.New("gone"))
+
+		_, err = exporter.Collect(t.Context(), db)
+
+		assert.ErrorContains(t, err, "SHOW GLOBAL STATUS")
+	})
+}
+
+func TestExporterValidate(t *testing.T) {
+	t.Run("should accept the defaults", func(t *testing.T) {
+		exporter := metrics.Exporter{Status: metrics.DefaultStatus, Variables: metrics.DefaultVariables}
+
+		assert.NoError(t, exporter.Validate())
+	})
+
+	t.Run("should reject invalid names", func(t *testing.T) {
+		err := metrics.Exporter{Variables: []string{"max_connections'"}}.Validate()
+
+		assert.ErrorContains(t, err, "invalid variable name")
+	})
+}
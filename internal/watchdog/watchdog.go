@@ -0,0 +1,124 @@
+// Package watchdog tracks the liveness of the sidecar itself: background
+// loops report heartbeats and request handlers register in-flight work, so a
+// hung goroutine can be detected without touching the database.
+package watchdog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// missedBeats is how many heartbeat intervals a loop may miss before it is
+// considered stuck.
+const missedBeats = 3
+
+type loop struct {
+	interval time.Duration
+	last     time.Time
+}
+
+// Watchdog is safe for concurrent use. All methods on a nil *Watchdog are
+// no-ops and report no problems.
+type Watchdog struct {
+	mu       sync.Mutex
+	loops    map[string]*loop
+	inflight map[uint64]time.Time
+	next     uint64
+	now      func() time.Time
+}
+
+// New returns an empty watchdog.
+func New() *Watchdog {
+	return &Watchdog{
+		loops:    map[string]*loop{},
+		inflight: map[uint64]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Register announces a background loop that calls Beat about every interval.
+func (w *Watchdog) Register(name string, interval time.Duration) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.loops[name] = &loop{interval: interval, last: w.now()}
+}
+
+// Beat records a heartbeat of the named loop.
+func (w *Watchdog) Beat(name string) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if l, ok := w.loops[name]; ok {
+		l.last = w.now()
+	}
+}
+
+// Track registers a unit of in-flight work. The returned function must be
+// called once the work is done.
+func (w *Watchdog) Track() func() {
+	if w == nil {
+		return func() {}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.next
+	w.next++
+	w.inflight[id] = w.now()
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		delete(w.inflight, id)
+	}
+}
+
+// Problems lists loops that missed their heartbeats and in-flight work
+// running for longer than maxInflight. An empty result means healthy.
+func (w *Watchdog) Problems(maxInflight time.Duration) []string {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+
+	var problems []string
+
+	for name, l := range w.loops {
+		if age := now.Sub(l.last); age > l.interval*missedBeats {
+			problems = append(problems, fmt.Sprintf("%s missed heartbeats for %s", name, age.Round(time.Second)))
+		}
+	}
+
+	hung := 0
+
+	for _, started := range w.inflight {
+		if now.Sub(started) > maxInflight {
+			hung++
+		}
+	}
+
+	if hung > 0 {
+		problems = append(problems, fmt.Sprintf("%d request(s) running for longer than %s", hung, maxInflight))
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
@@ -0,0 +1,68 @@
+package watchdog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWatchdog() (*Watchdog, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := New()
+	w.now = func() time.Time { return now }
+
+	return w, &now
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Run("should report no problems when loops beat in time", func(t *testing.T) {
+		w, now := newTestWatchdog()
+		w.Register("loop", time.Minute)
+
+		*now = now.Add(2 * time.Minute)
+		w.Beat("loop")
+		*now = now.Add(2 * time.Minute)
+
+		assert.Empty(t, w.Problems(time.Minute))
+	})
+
+	t.Run("should report a loop that missed its heartbeats", func(t *testing.T) {
+		w, now := newTestWatchdog()
+		w.Register("loop", time.Minute)
+
+		*now = now.Add(4 * time.Minute)
+
+		assert.Equal(t, []string{"loop missed heartbeats for 4m0s"}, w.Problems(time.Minute))
+	})
+
+	t.Run("should report hung in-flight work", func(t *testing.T) {
+		w, now := newTestWatchdog()
+
+		done := w.Track()
+		w.Track()
+
+		*now = now.Add(time.Minute)
+		done()
+
+		assert.Equal(t, []string{"1 request(s) running for longer than 15s"}, w.Problems(15*time.Second))
+	})
+
+	t.Run("should ignore beats of unregistered loops", func(t *testing.T) {
+		w, _ := newTestWatchdog()
+
+		w.Beat("unknown")
+
+		assert.Empty(t, w.Problems(time.Minute))
+	})
+
+	t.Run("should be a no-op on nil", func(t *testing.T) {
+		var w *Watchdog
+
+		w.Register("loop", time.Second)
+		w.Beat("loop")
+		w.Track()()
+
+		assert.Empty(t, w.Problems(time.Second))
+	})
+}
@@ -0,0 +1,257 @@
+// Package webhook posts health state transitions to external HTTP endpoints
+// such as Alertmanager or any CloudEvents consumer.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of the request body,
+// prefixed with "sha256=", when a secret is configured.
+const SignatureHeader = "X-Healthcheck-Signature"
+
+const queueSize = 16
+
+var errStatus = errors.New("unexpected status code")
+
+// Config configures a Notifier.
+type Config struct {
+	URLs []string
+	// Format is FormatCloudEvents or FormatAlertmanager.
+	Format string
+	// Secret is the HMAC key used to sign every request. Empty disables
+	// signing.
+	Secret string
+	// Source identifies this sidecar in the payload, usually the pod name.
+	Source string
+	// MaxRetries is the number of additional attempts per URL after the
+	// first one fails.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on every
+	// further attempt.
+	RetryBackoff time.Duration
+	// MinInterval is the minimum time between two notifications. Transitions
+	// arriving sooner are coalesced into one.
+	MinInterval time.Duration
+	// Timeout bounds a single HTTP attempt.
+	Timeout time.Duration
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if len(c.URLs) == 0 {
+		return fmt.Errorf("no webhook URLs configured")
+	}
+
+	if !validFormat(c.Format) {
+		return fmt.Errorf("invalid webhook format: %q", c.Format)
+	}
+
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("invalid max retries: %d", c.MaxRetries)
+	}
+
+	return nil
+}
+
+// Notifier delivers transitions asynchronously so that probes are never
+// blocked by a slow receiver. All methods on a nil *Notifier are no-ops.
+type Notifier struct {
+	cfg    Config
+	client *http.Client
+	events chan health.Transition
+	last   time.Time
+}
+
+// New returns a notifier for cfg. Run must be called to start delivery.
+func New(cfg Config) (*Notifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate webhook config: %w", err)
+	}
+
+	return &Notifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		events: make(chan health.Transition, queueSize),
+	}, nil
+}
+
+// Notify queues transition for delivery. It never blocks; when the queue is
+// full the transition is dropped and logged.
+func (n *Notifier) Notify(transition health.Transition) {
+	if n == nil {
+		return
+	}
+
+	select {
+	case n.events <- transition:
+	default:
+		slog.Warn(
+			"webhook queue is full, dropping transition",
+			"from", transition.From,
+			"to", transition.To,
+		)
+	}
+}
+
+// Run delivers queued transitions until ctx is canceled.
+func (n *Notifier) Run(ctx context.Context) {
+	if n == nil {
+		return
+	}
+
+	for {
+		var pending health.Transition
+
+		select {
+		case <-ctx.Done():
+			return
+		case pending = <-n.events:
+		}
+
+		pending, ok := n.throttle(ctx, pending)
+		if !ok {
+			return
+		}
+
+		// A flap that settled back where it started is not worth a page.
+		if pending.From == pending.To {
+			continue
+		}
+
+		n.last = time.Now()
+		n.deliver(ctx, pending)
+	}
+}
+
+// throttle waits until MinInterval has passed since the last delivery,
+// merging any transitions that arrive meanwhile into pending. It returns
+// false when ctx is canceled.
+func (n *Notifier) throttle(ctx context.Context, pending health.Transition) (health.Transition, bool) {
+	wait := time.Until(n.last.Add(n.cfg.MinInterval))
+	if wait <= 0 {
+		return pending, true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return pending, false
+		case <-timer.C:
+			return pending, true
+		case next := <-n.events:
+			pending.To = next.To
+			pending.Time = next.Time
+			pending.Reason = next.Reason
+		}
+	}
+}
+
+func (n *Notifier) deliver(ctx context.Context, transition health.Transition) {
+	body, contentType, err := encode(n.cfg.Format, n.cfg.Source, transition)
+	if err != nil {
+		slog.Error("failed to encode webhook payload", "error", err)
+		return
+	}
+
+	for _, url := range n.cfg.URLs {
+		if err := n.post(ctx, url, body, contentType); err != nil {
+			slog.Error(
+				"failed to deliver webhook",
+				"url", url,
+				"from", transition.From,
+				"to", transition.To,
+				"error", err,
+			)
+
+			continue
+		}
+
+		slog.Info(
+			"delivered webhook",
+			"url", url,
+			"from", transition.From,
+			"to", transition.To,
+		)
+	}
+}
+
+// post sends body to url, retrying with exponential backoff.
+func (n *Notifier) post(ctx context.Context, url string, body []byte, contentType string) error {
+	backoff := n.cfg.RetryBackoff
+
+	var err error
+
+	for attempt := 0; attempt <= n.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("webhook canceled: %w", ctx.Err())
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+
+		err = n.attempt(ctx, url, body, contentType)
+		if err == nil {
+			return nil
+		}
+
+		slog.Debug(
+			"webhook attempt failed",
+			"url", url,
+			"attempt", attempt+1,
+			"error", err,
+		)
+	}
+
+	return err
+}
+
+func (n *Notifier) attempt(ctx context.Context, url string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	if n.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.cfg.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", errStatus, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,213 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type received struct {
+	body        []byte
+	contentType string
+	signature   string
+}
+
+// newReceiver starts a local stand-in for a webhook consumer. The first
+// failures requests are answered with 500.
+func newReceiver(t *testing.T, failures int32) (*httptest.Server, chan received) {
+	t.Helper()
+
+	ch := make(chan received, 10)
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		ch <- received{
+			body:        body,
+			contentType: r.Header.Get("Content-Type"),
+			signature:   r.Header.Get(webhook.SignatureHeader),
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, ch
+}
+
+func waitFor(t *testing.T, ch chan received) received {
+	t.Helper()
+
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	return received{}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("should return error without URLs", func(t *testing.T) {
+		err := webhook.Config{Format: webhook.FormatCloudEvents}.Validate()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "no webhook URLs configured")
+	})
+
+	t.Run("should return error for unknown format", func(t *testing.T) {
+		err := webhook.Config{URLs: []string{"http://x"}, Format: "slack"}.Validate()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid webhook format")
+	})
+
+	t.Run("should return error for negative retries", func(t *testing.T) {
+		err := webhook.Config{
+			URLs:       []string{"http://x"},
+			Format:     webhook.FormatAlertmanager,
+			MaxRetries: -1,
+		}.Validate()
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "invalid max retries")
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	transition := health.Transition{
+		From:   health.StatusHealthy,
+		To:     health.StatusUnhealthy,
+		Time:   time.Now(),
+		Reason: "failed to insert row",
+	}
+
+	t.Run("should post a signed cloud event", func(t *testing.T) {
+		server, ch := newReceiver(t, 0)
+
+		notifier, err := webhook.New(webhook.Config{
+			URLs:    []string{server.URL},
+			Format:  webhook.FormatCloudEvents,
+			Secret:  "s3cret",
+			Source:  "mariadb-0",
+			Timeout: time.Second,
+		})
+		require.NoError(t, err)
+
+		go notifier.Run(t.Context())
+		notifier.Notify(transition)
+
+		got := waitFor(t, ch)
+
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(got.body, &event))
+		assert.Equal(t, "1.0", event["specversion"])
+		assert.Equal(t, "mariadb-0", event["source"])
+		assert.Equal(t, map[string]any{
+			"from":   "healthy",
+			"to":     "unhealthy",
+			"reason": "failed to insert row",
+		}, event["data"])
+		assert.Equal(t, "application/cloudevents+json", got.contentType)
+		assert.Equal(t, "sha256="+webhook.Sign("s3cret", got.body), got.signature)
+	})
+
+	t.Run("should post a resolved alert when back to healthy", func(t *testing.T) {
+		server, ch := newReceiver(t, 0)
+
+		notifier, err := webhook.New(webhook.Config{
+			URLs:    []string{server.URL},
+			Format:  webhook.FormatAlertmanager,
+			Source:  "mariadb-0",
+			Timeout: time.Second,
+		})
+		require.NoError(t, err)
+
+		go notifier.Run(t.Context())
+		notifier.Notify(health.Transition{
+			From: health.StatusUnhealthy,
+			To:   health.StatusHealthy,
+			Time: time.Now(),
+		})
+
+		got := waitFor(t, ch)
+
+		var alerts []map[string]any
+		require.NoError(t, json.Unmarshal(got.body, &alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "mariadb-0", alerts[0]["labels"].(map[string]any)["instance"])
+		assert.Contains(t, alerts[0], "endsAt")
+		assert.Empty(t, got.signature)
+	})
+
+	t.Run("should retry failed deliveries", func(t *testing.T) {
+		server, ch := newReceiver(t, 2)
+
+		notifier, err := webhook.New(webhook.Config{
+			URLs:         []string{server.URL},
+			Format:       webhook.FormatCloudEvents,
+			MaxRetries:   2,
+			RetryBackoff: time.Millisecond,
+			Timeout:      time.Second,
+		})
+		require.NoError(t, err)
+
+		go notifier.Run(t.Context())
+		notifier.Notify(transition)
+
+		waitFor(t, ch)
+	})
+
+	t.Run("should coalesce transitions within the rate limit", func(t *testing.T) {
+		server, ch := newReceiver(t, 0)
+
+		notifier, err := webhook.New(webhook.Config{
+			URLs:        []string{server.URL},
+			Format:      webhook.FormatCloudEvents,
+			MinInterval: 200 * time.Millisecond,
+			Timeout:     time.Second,
+		})
+		require.NoError(t, err)
+
+		go notifier.Run(t.Context())
+		notifier.Notify(transition)
+		waitFor(t, ch)
+
+		notifier.Notify(health.Transition{From: health.StatusUnhealthy, To: health.StatusDegraded})
+		notifier.Notify(health.Transition{From: health.StatusDegraded, To: health.StatusHealthy})
+
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(waitFor(t, ch).body, &event))
+		assert.Equal(t, "unhealthy", event["data"].(map[string]any)["from"])
+		assert.Equal(t, "healthy", event["data"].(map[string]any)["to"])
+
+		select {
+		case <-ch:
+			t.Fatal("coalesced transitions were delivered more than once")
+		case <-time.After(300 * time.Millisecond):
+		}
+	})
+
+	t.Run("should be a no-op on a nil notifier", func(_ *testing.T) {
+		var notifier *webhook.Notifier
+
+		notifier.Notify(transition)
+		notifier.Run(t.Context())
+	})
+}
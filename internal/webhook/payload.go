@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+)
+
+// Supported payload formats.
+const (
+	FormatCloudEvents  = "cloudevents"
+	FormatAlertmanager = "alertmanager"
+)
+
+const (
+	cloudEventType        = "io.github.richie-tt.mariadb-healthcheck.transition"
+	cloudEventContentType = "application/cloudevents+json"
+	alertName             = "MariaDBHealthTransition"
+)
+
+// transitionData is the format-independent description of a transition.
+type transitionData struct {
+	From   health.Status `json:"from"`
+	To     health.Status `json:"to"`
+	Reason string        `json:"reason,omitempty"`
+}
+
+// cloudEvent is a CloudEvents 1.0 event in structured JSON mode.
+//
+//nolint:tagliatelle // attribute names are fixed by the CloudEvents spec
+type cloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Time            time.Time      `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            transitionData `json:"data"`
+}
+
+// alert is a single alert as accepted by the Alertmanager v2 API.
+type alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt,omitzero"`
+}
+
+// validFormat reports whether format is one of the supported payload formats.
+func validFormat(format string) bool {
+	return format == FormatCloudEvents || format == FormatAlertmanager
+}
+
+// encode renders transition in the given format and returns the body along
+// with its content type.
+func encode(format, source string, transition health.Transition) ([]byte, string, error) {
+	data := transitionData{
+		From:   transition.From,
+		To:     transition.To,
+		Reason: transition.Reason,
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+
+	switch format {
+	case FormatAlertmanager:
+		entry := alert{
+			// Labels identify the alert, so they must not change between the
+			// firing and the resolving notification.
+			Labels: map[string]string{
+				"alertname": alertName,
+				"instance":  source,
+			},
+			Annotations: map[string]string{
+				"summary":     fmt.Sprintf("MariaDB is %s (was %s)", transition.To, transition.From),
+				"description": transition.Reason,
+				"state":       transition.To.String(),
+			},
+			StartsAt: transition.Time,
+		}
+
+		// Alertmanager resolves an alert once EndsAt is in the past.
+		if transition.To == health.StatusHealthy {
+			entry.EndsAt = transition.Time
+		}
+
+		body, err = json.Marshal([]alert{entry})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode alert: %w", err)
+		}
+
+		return body, "application/json", nil
+	default:
+		body, err = json.Marshal(cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              uuid.NewString(),
+			Source:          source,
+			Type:            cloudEventType,
+			Time:            transition.Time,
+			DataContentType: "application/json",
+			Data:            data,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode cloud event: %w", err)
+		}
+
+		return body, cloudEventContentType, nil
+	}
+}
@@ -0,0 +1,162 @@
+package healthcheck
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// The names of the built-in checks, as picked with ?checks=.
+const (
+	CheckConnections  = mariadb.CheckConnections
+	CheckInnoDB       = mariadb.CheckInnoDB
+	CheckResources    = mariadb.CheckResources
+	CheckSemiSync     = mariadb.CheckSemiSync
+	CheckTransactions = mariadb.CheckTransactions
+)
+
+type (
+	// ConnectionThresholds are the share of max_connections in use, in
+	// percent, at which the connections check turns degraded or unhealthy.
+	ConnectionThresholds = mariadb.ConnectionThresholds
+	// InnoDBThresholds are the levels at which the innodb check turns
+	// degraded.
+	InnoDBThresholds = mariadb.InnoDBThresholds
+	// ResourceThresholds are the levels at which the resources check turns
+	// degraded.
+	ResourceThresholds = mariadb.ResourceThresholds
+	// TransactionThresholds are the ages at which the transactions check
+	// turns degraded.
+	TransactionThresholds = mariadb.TransactionThresholds
+)
+
+// The categories a round-trip failure is classified into.
+const (
+	CategoryAuthentication     = mariadb.CategoryAuthentication
+	CategoryUnknownDatabase    = mariadb.CategoryUnknownDatabase
+	CategoryPrivileges         = mariadb.CategoryPrivileges
+	CategoryTooManyConnections = mariadb.CategoryTooManyConnections
+	CategoryReadOnly           = mariadb.CategoryReadOnly
+	CategoryLock               = mariadb.CategoryLock
+	CategoryDiskFull           = mariadb.CategoryDiskFull
+	CategoryUnknownTable       = mariadb.CategoryUnknownTable
+	CategoryNetwork            = mariadb.CategoryNetwork
+	CategoryUnknown            = mariadb.CategoryUnknown
+)
+
+// NewConnectionCheck returns the connections check: connections in use
+// compared with max_connections, and connections refused since the previous
+// comparison. It only counts against readiness.
+func NewConnectionCheck(thresholds ConnectionThresholds) (Checker, error) {
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	return mariadb.NewConnectionCheck(thresholds), nil
+}
+
+// NewInnoDBCheck returns the innodb check: purge lag, checkpoint age, pending
+// I/O, buffer pool hit ratio and deadlocks. It needs the PROCESS privilege
+// and only counts against readiness.
+func NewInnoDBCheck(thresholds InnoDBThresholds) (Checker, error) {
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	return mariadb.NewInnoDBCheck(thresholds), nil
+}
+
+// NewResourceCheck returns the resources check: open files, table cache,
+// thread cache and temporary tables on disk. It only counts against
+// readiness.
+func NewResourceCheck(thresholds ResourceThresholds) (Checker, error) {
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	return mariadb.NewResourceCheck(thresholds), nil
+}
+
+// NewSemiSyncCheck returns the semi_sync check of a primary that needs
+// minClients semi-synchronous replicas. It only counts against readiness.
+func NewSemiSyncCheck(minClients uint64) Checker {
+	return mariadb.NewSemiSyncCheck(minClients)
+}
+
+// NewTransactionCheck returns the transactions check: long-running
+// transactions and metadata lock waits. The offenders' query text is only
+// listed with showQueries, as the verbose output usually needs no
+// authentication. It needs the PROCESS privilege and only counts against
+// readiness.
+func NewTransactionCheck(thresholds TransactionThresholds, showQueries bool) (Checker, error) {
+	if err := thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid thresholds: %w", err)
+	}
+
+	check := mariadb.NewTransactionCheck(thresholds)
+	check.ShowQueries = showQueries
+
+	return check, nil
+}
+
+// ParseScope parses a probe scope: liveness, readiness, both or none.
+func ParseScope(value string) (Scope, error) {
+	switch value {
+	case "liveness":
+		return ScopeLiveness, nil
+	case "readiness":
+		return ScopeReadiness, nil
+	case "both":
+		return ScopeAll, nil
+	case "none":
+		return ScopeNone, nil
+	default:
+		return ScopeNone, fmt.Errorf("invalid scope %q, available scopes: liveness, readiness, both, none", value)
+	}
+}
+
+// ParseErrorScopes parses a comma-separated list of category:scope pairs,
+// e.g. "too_many_connections:readiness,lock:none", as taken by the sidecar's
+// ERROR_SCOPES.
+func ParseErrorScopes(value string) (map[ErrorCategory]Scope, error) {
+	scopes := map[ErrorCategory]Scope{}
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		name, rawScope, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q, expected category:scope", item)
+		}
+
+		category := ErrorCategory(strings.TrimSpace(name))
+		if !slices.Contains(mariadb.Categories, category) {
+			return nil, fmt.Errorf("unknown error category %q", category)
+		}
+
+		scope, err := ParseScope(strings.TrimSpace(rawScope))
+		if err != nil {
+			return nil, err
+		}
+
+		scopes[category] = scope
+	}
+
+	return scopes, nil
+}
+
+// ErrorScopes returns an Options.ErrorScope looking the category up in
+// scopes. Categories missing from scopes count against every probe.
+func ErrorScopes(scopes map[ErrorCategory]Scope) func(ErrorCategory) Scope {
+	return func(category ErrorCategory) Scope {
+		if scope, ok := scopes[category]; ok {
+			return scope
+		}
+
+		return ScopeAll
+	}
+}
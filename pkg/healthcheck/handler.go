@@ -0,0 +1,146 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// response is the body returned for ?verbose=true.
+type response struct {
+	Status Status   `json:"status"`
+	Probe  Probe    `json:"probe"`
+	Checks []Result `json:"checks"`
+}
+
+// ServeHTTP checks the database and answers with 200 when the probe passes,
+// 503 when a check fails it and 500 when the round-trip does. The body is a
+// short message, or the report as JSON for ?verbose=true.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	req, err := h.parseRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeBody(w, err.Error())
+
+		return
+	}
+
+	probe := req.probe
+
+	if h.options.Intercept != nil && h.options.Intercept(w, probe) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), req.timeout)
+	defer cancel()
+
+	outcome := h.run(ctx, req)
+	report := outcome.Report
+
+	status := report.Status(probe.Scope())
+	worst, failing := report.Worst(probe.Scope())
+
+	for _, rt := range outcome.RoundTrips {
+		if rt.Err != nil {
+			slog.ErrorContext(ctx, "healthcheck failed",
+				"probe", probe,
+				"check", rt.Name,
+				"category", mariadb.Classify(rt.Err),
+				"error", rt.Err,
+			)
+		}
+	}
+
+	code := http.StatusOK
+
+	if status == StatusUnhealthy {
+		code = http.StatusServiceUnavailable
+
+		if IsRoundTrip(worst.Name) {
+			code = http.StatusInternalServerError
+		} else {
+			slog.ErrorContext(ctx, "healthcheck failed", "probe", probe, "check", worst.Name, "error", worst.Message)
+		}
+	}
+
+	if req.verbose {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+
+		if err := json.NewEncoder(w).Encode(response{Status: status, Probe: probe, Checks: report.Results}); err != nil {
+			slog.Error("failed to write body", "error", err)
+		}
+
+		return
+	}
+
+	w.WriteHeader(code)
+
+	if !failing {
+		writeBody(w, "OK")
+		return
+	}
+
+	message := worst.Name + ": " + worst.Message
+
+	switch {
+	case worst.Name == CheckRoundTrip:
+		message = roundTripMessage(outcome.ErrOf(worst.Name))
+	case IsRoundTrip(worst.Name):
+		message = worst.Name + ": " + roundTripMessage(outcome.ErrOf(worst.Name))
+	}
+
+	if status == StatusDegraded {
+		message = "degraded: " + message
+	}
+
+	writeBody(w, message)
+}
+
+// roundTripMessage maps a round-trip error to the stable response body,
+// followed by the error category when the cause was recognised.
+func roundTripMessage(err error) string {
+	if category := mariadb.Classify(err); category != mariadb.CategoryUnknown {
+		return stageMessage(err) + ": " + string(category)
+	}
+
+	return stageMessage(err)
+}
+
+func stageMessage(err error) string {
+	switch {
+	case errors.Is(err, mariadb.ErrInsert):
+		return "failed to insert row"
+	case errors.Is(err, mariadb.ErrSelect):
+		return "failed to select row"
+	case errors.Is(err, mariadb.ErrScan):
+		return "failed to scan row"
+	case errors.Is(err, mariadb.ErrValidate):
+		return "failed to validate row"
+	case errors.Is(err, mariadb.ErrDelete):
+		return "failed to delete row"
+	case errors.Is(err, mariadb.ErrPing):
+		return "failed to ping server"
+	case errors.Is(err, mariadb.ErrRead):
+		return "failed to read status table"
+	default:
+		return "healthcheck failed"
+	}
+}
+
+func writeBody(w http.ResponseWriter, message string) {
+	_, err := w.Write([]byte(message))
+	if err != nil {
+		slog.Error(
+			"failed to write body",
+			"message", message,
+			"error", err,
+		)
+	}
+}
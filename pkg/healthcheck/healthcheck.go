@@ -0,0 +1,214 @@
+// Package healthcheck checks a MariaDB or MySQL server the way the
+// mariadb-healthcheck sidecar does, so it can be embedded in another binary,
+// e.g. an operator or an admin server.
+//
+// A Handler runs an INSERT -> SELECT -> DELETE round-trip against one or more
+// status tables, followed by optional checks. Check returns the outcome;
+// ServeHTTP serves it with the same query parameters and responses as the
+// sidecar's /health endpoint.
+//
+// The sidecar's optional checks are built with NewConnectionCheck,
+// NewInnoDBCheck, NewResourceCheck, NewSemiSyncCheck and NewTransactionCheck;
+// ParseErrorScopes and ErrorScopes turn its ERROR_SCOPES syntax into an
+// Options.ErrorScope.
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/richie-tt/mariadb-healthcheck/internal/health"
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+type (
+	// DB is the part of *sql.DB the checks use; a *sql.DB satisfies it.
+	DB = health.DB
+	// Checker is an optional check run after the round-trip succeeded.
+	Checker = health.Checker
+	// Result is the outcome of a single check.
+	Result = health.Result
+	// Report is the set of results produced for a single check.
+	Report = health.Report
+	// Status is the health of a check: healthy, degraded or unhealthy.
+	Status = health.Status
+	// Scope tells which probes a failing check counts against.
+	Scope = health.Scope
+	// Mode is the depth of the round-trip: ping, read or write.
+	Mode = mariadb.Mode
+	// Stage is a timed step of the round-trip, e.g. insert.
+	Stage = mariadb.Stage
+	// ErrorCategory classifies why the round-trip failed, e.g. disk_full.
+	ErrorCategory = mariadb.ErrorCategory
+)
+
+const (
+	StatusHealthy   = health.StatusHealthy
+	StatusDegraded  = health.StatusDegraded
+	StatusUnhealthy = health.StatusUnhealthy
+
+	ScopeLiveness  = health.ScopeLiveness
+	ScopeReadiness = health.ScopeReadiness
+	ScopeNone      = health.ScopeNone
+	ScopeAll       = health.ScopeAll
+
+	ModePing  = mariadb.ModePing
+	ModeRead  = mariadb.ModeRead
+	ModeWrite = mariadb.ModeWrite
+)
+
+// DefaultTimeout bounds a check when Options.Timeout is not set.
+const DefaultTimeout = 5 * time.Second
+
+// Table is a status table the round-trip runs against, e.g. one per storage
+// engine, and the probes its failure counts against.
+type Table struct {
+	Name  string
+	Scope Scope
+}
+
+// Options configures a Handler. Only DB is required.
+type Options struct {
+	// DB is the database to check, e.g. a *sql.DB opened by the caller.
+	DB DB
+	// Tables are the tables the round-trip runs against; empty means the
+	// default status table counting against every probe.
+	Tables []Table
+	// KeepRows leaves the inserted row in place instead of deleting it.
+	KeepRows bool
+	// Checks run after every successful round-trip unless a request picks
+	// its own with ?checks=.
+	Checks []Checker
+	// Available are the further checks a request may pick with ?checks=. The
+	// Checks are always available.
+	Available []Checker
+	// Gates run after every successful round-trip, before the Checks and
+	// regardless of what a request picks.
+	Gates []Checker
+	// ErrorScope returns the probes a round-trip failure of the given
+	// category counts against; nil counts every failure against every probe.
+	ErrorScope func(ErrorCategory) Scope
+	// Timeout bounds a check; DefaultTimeout when zero. Requests may only
+	// lower it.
+	Timeout time.Duration
+	// StageTimeout bounds every round-trip stage; zero leaves it to Timeout.
+	StageTimeout time.Duration
+	// Intercept may answer a request before the database is touched, e.g.
+	// while shutting down. It reports whether it did.
+	Intercept func(w http.ResponseWriter, probe Probe) bool
+	// Observe is called with the outcome of every check, e.g. to record it.
+	Observe func(Outcome)
+}
+
+// Handler checks the database and serves the outcome over HTTP.
+type Handler struct {
+	options   Options
+	available map[string]Checker
+}
+
+// New returns a Handler checking options.DB.
+func New(options Options) (*Handler, error) {
+	if options.DB == nil {
+		return nil, errors.New("a database is required")
+	}
+
+	if options.Timeout < 0 {
+		return nil, fmt.Errorf("invalid timeout %s", options.Timeout)
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	if options.StageTimeout < 0 || options.StageTimeout > options.Timeout {
+		return nil, fmt.Errorf("stage timeout %s must be between 0 and the timeout %s",
+			options.StageTimeout, options.Timeout)
+	}
+
+	if len(options.Tables) == 0 {
+		options.Tables = []Table{{Name: mariadb.DefaultTable, Scope: ScopeAll}}
+	}
+
+	for i, table := range options.Tables {
+		if !mariadb.IsTableName(table.Name) {
+			return nil, fmt.Errorf("invalid table name %q", table.Name)
+		}
+
+		if slices.ContainsFunc(options.Tables[:i], func(t Table) bool { return t.Name == table.Name }) {
+			return nil, fmt.Errorf("duplicate table %q", table.Name)
+		}
+	}
+
+	available := map[string]Checker{}
+
+	for _, check := range slices.Concat(options.Available, options.Checks) {
+		available[check.Name()] = check
+	}
+
+	return &Handler{options: options, available: available}, nil
+}
+
+// Check runs a write round-trip and the Checks, bounded by the timeout.
+func (h *Handler) Check(ctx context.Context) Outcome {
+	ctx, cancel := context.WithTimeout(ctx, h.options.Timeout)
+	defer cancel()
+
+	return h.run(ctx, request{probe: ProbeUnknown, mode: ModeWrite, checks: h.options.Checks})
+}
+
+// run runs the round-trip and the checks of req and hands the outcome to
+// Observe.
+func (h *Handler) run(ctx context.Context, req request) Outcome {
+	id := uuid.New()
+
+	slog.Debug(
+		"generated UUID",
+		"value", id,
+	)
+
+	outcome := Outcome{
+		Probe: req.probe,
+		Full:  req.mode == ModeWrite && !req.picked,
+		Start: time.Now(),
+	}
+
+	outcome.RoundTrips = h.roundTrip(ctx, req.mode, id.String())
+	outcome.Report = h.report(ctx, req.checks, outcome.RoundTrips)
+
+	if h.options.Observe != nil {
+		h.options.Observe(outcome)
+	}
+
+	return outcome
+}
+
+// availableNames returns the names of the available checks, sorted.
+func (h *Handler) availableNames() string {
+	return strings.Join(slices.Sorted(maps.Keys(h.available)), ", ")
+}
+
+// CheckerFunc returns a Checker named name that runs check.
+func CheckerFunc(name string, check func(ctx context.Context, db DB) Result) Checker {
+	return checkerFunc{name: name, check: check}
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context, db DB) Result
+}
+
+func (c checkerFunc) Name() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context, db DB) Result {
+	return c.check(ctx, db)
+}
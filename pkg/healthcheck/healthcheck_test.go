@@ -0,0 +1,325 @@
+package healthcheck_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/richie-tt/mariadb-healthcheck/pkg/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRoundTripDB returns a mock database expecting a successful round-trip
+// including DELETE.
+func newRoundTripDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT uuid FROM status WHERE uuid = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow("any-uuid"))
+	mock.ExpectExec("DELETE FROM status WHERE uuid = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	return db
+}
+
+// fixed returns a check named name reporting status for readiness only.
+func fixed(name string, status healthcheck.Status) healthcheck.Checker {
+	return healthcheck.CheckerFunc(name, func(context.Context, healthcheck.DB) healthcheck.Result {
+		return healthcheck.Result{Name: name, Status: status, Scope: healthcheck.ScopeReadiness, Message: status.String()}
+	})
+}
+
+// pingCounter wraps a DB the way a caller would, e.g. for tracing.
+type pingCounter struct {
+	healthcheck.DB
+	pings int
+}
+
+func (p *pingCounter) PingContext(ctx context.Context) error {
+	p.pings++
+
+	return p.DB.PingContext(ctx)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should return error for invalid options", func(t *testing.T) {
+		db := newRoundTripDB(t)
+
+		for message, options := range map[string]healthcheck.Options{
+			"a database is required":   {},
+			"invalid timeout -1s":      {DB: db, Timeout: -time.Second},
+			"must be between 0 and":    {DB: db, StageTimeout: healthcheck.DefaultTimeout + 1},
+			`invalid table name "a;b"`: {DB: db, Tables: []healthcheck.Table{{Name: "a;b"}}},
+			`duplicate table "status"`: {DB: db, Tables: []healthcheck.Table{{Name: "status"}, {Name: "status"}}},
+		} {
+			_, err := healthcheck.New(options)
+
+			assert.ErrorContains(t, err, message)
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("should run the round-trip and the checks", func(t *testing.T) {
+		var observed []healthcheck.Outcome
+
+		checker, err := healthcheck.New(healthcheck.Options{
+			DB:      newRoundTripDB(t),
+			Checks:  []healthcheck.Checker{fixed("connections", healthcheck.StatusHealthy)},
+			Observe: func(outcome healthcheck.Outcome) { observed = append(observed, outcome) },
+		})
+		require.NoError(t, err)
+
+		outcome := checker.Check(t.Context())
+
+		require.NoError(t, outcome.Err())
+		assert.Equal(t, healthcheck.StatusHealthy, outcome.Report.Status(healthcheck.ScopeAll))
+		require.Len(t, outcome.Report.Results, 2)
+		assert.Equal(t, healthcheck.CheckRoundTrip, outcome.Report.Results[0].Name)
+		assert.Equal(t, "connections", outcome.Report.Results[1].Name)
+		assert.Len(t, outcome.Stages(), 3)
+		assert.Len(t, observed, 1)
+	})
+
+	t.Run("should skip the checks when the round-trip failed", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO status (uuid) VALUES (?)").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(&mysql.MySQLError{Number: 1021, Message: "Disk full"})
+
+		checker, err := healthcheck.New(healthcheck.Options{
+			DB:     db,
+			Checks: []healthcheck.Checker{fixed("connections", healthcheck.StatusHealthy)},
+			ErrorScope: func(healthcheck.ErrorCategory) healthcheck.Scope {
+				return healthcheck.ScopeReadiness
+			},
+		})
+		require.NoError(t, err)
+
+		outcome := checker.Check(t.Context())
+
+		require.Error(t, outcome.Err())
+		require.Len(t, outcome.Report.Results, 1)
+		assert.Equal(t, healthcheck.StatusUnhealthy, outcome.Report.Status(healthcheck.ScopeReadiness))
+		assert.Equal(t, healthcheck.StatusDegraded, outcome.Report.Status(healthcheck.ScopeLiveness))
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	serve := func(t *testing.T, options healthcheck.Options, target string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		checker, err := healthcheck.New(options)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		checker.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return w
+	}
+
+	t.Run("should serve the report as JSON", func(t *testing.T) {
+		options := healthcheck.Options{
+			DB:     newRoundTripDB(t),
+			Checks: []healthcheck.Checker{fixed("connections", healthcheck.StatusUnhealthy)},
+		}
+
+		w := serve(t, options, "/health?probe=readiness&verbose=true")
+
+		var body struct {
+			Status string `json:"status"`
+			Probe  string `json:"probe"`
+			Checks []struct {
+				Name string `json:"name"`
+			} `json:"checks"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "unhealthy", body.Status)
+		assert.Equal(t, "readiness", body.Probe)
+		assert.Len(t, body.Checks, 2)
+	})
+
+	t.Run("should run the gates regardless of the requested checks", func(t *testing.T) {
+		options := healthcheck.Options{
+			DB:        newRoundTripDB(t),
+			Available: []healthcheck.Checker{fixed("connections", healthcheck.StatusHealthy)},
+			Gates:     []healthcheck.Checker{fixed("version", healthcheck.StatusUnhealthy)},
+		}
+
+		w := serve(t, options, "/health?probe=readiness&checks=connections")
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "version: unhealthy", w.Body.String())
+	})
+
+	t.Run("should ping through a caller supplied database", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectPing()
+
+		counter := &pingCounter{DB: db}
+
+		w := serve(t, healthcheck.Options{DB: counter}, "/health?mode=ping")
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "OK", w.Body.String())
+		assert.Equal(t, 1, counter.pings)
+	})
+
+	t.Run("should let Intercept answer first", func(t *testing.T) {
+		// The mock expects nothing: any query would fail the probe.
+		db, _, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		options := healthcheck.Options{
+			DB: db,
+			Intercept: func(w http.ResponseWriter, _ healthcheck.Probe) bool {
+				w.WriteHeader(http.StatusTeapot)
+
+				return true
+			},
+		}
+
+		w := serve(t, options, "/health?probe=readiness")
+
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("should return errors for invalid parameters", func(t *testing.T) {
+		options := healthcheck.Options{
+			DB:     newRoundTripDB(t),
+			Checks: []healthcheck.Checker{fixed("connections", healthcheck.StatusHealthy)},
+		}
+
+		for target, message := range map[string]string{
+			"/health?mode=deep":          `invalid mode "deep"`,
+			"/health?checks=replication": `unknown check "replication", available checks: connections`,
+			"/health?timeout=-1s":        `invalid timeout "-1s"`,
+			"/health?timeout=soon":       `invalid timeout "soon"`,
+			"/health?verbose=loud":       `invalid verbose "loud"`,
+		} {
+			w := serve(t, options, target)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, target)
+			assert.Contains(t, w.Body.String(), message, target)
+		}
+	})
+}
+
+func TestProbeFromRequest(t *testing.T) {
+	t.Run("should return the declared probe", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/health?probe=readiness", nil)
+
+		assert.Equal(t, healthcheck.ProbeReadiness, healthcheck.ProbeFromRequest(r))
+		assert.Equal(t, healthcheck.ScopeReadiness, healthcheck.ProbeFromRequest(r).Scope())
+	})
+
+	t.Run("should return unknown for unrecognised values", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/health?probe=bogus", nil)
+
+		assert.Equal(t, healthcheck.ProbeUnknown, healthcheck.ProbeFromRequest(r))
+		assert.Equal(t, healthcheck.ScopeLiveness, healthcheck.ProbeFromRequest(r).Scope())
+	})
+}
+
+func TestParseErrorScopes(t *testing.T) {
+	t.Run("should parse category scope pairs", func(t *testing.T) {
+		scopes, err := healthcheck.ParseErrorScopes("too_many_connections:readiness, lock:none,network:both,authentication:liveness")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[healthcheck.ErrorCategory]healthcheck.Scope{
+			healthcheck.CategoryTooManyConnections: healthcheck.ScopeReadiness,
+			healthcheck.CategoryLock:               healthcheck.ScopeNone,
+			healthcheck.CategoryNetwork:            healthcheck.ScopeAll,
+			healthcheck.CategoryAuthentication:     healthcheck.ScopeLiveness,
+		}, scopes)
+	})
+
+	t.Run("should return error for missing scope", func(t *testing.T) {
+		_, err := healthcheck.ParseErrorScopes("lock")
+
+		assert.ErrorContains(t, err, "expected category:scope")
+	})
+
+	t.Run("should return error for unknown category", func(t *testing.T) {
+		_, err := healthcheck.ParseErrorScopes("cosmic_rays:none")
+
+		assert.ErrorContains(t, err, "unknown error category")
+	})
+
+	t.Run("should return error for unknown scope", func(t *testing.T) {
+		_, err := healthcheck.ParseErrorScopes("lock:sometimes")
+
+		assert.ErrorContains(t, err, "invalid scope")
+	})
+}
+
+func TestErrorScopes(t *testing.T) {
+	t.Run("should count unlisted categories against every probe", func(t *testing.T) {
+		scope := healthcheck.ErrorScopes(map[healthcheck.ErrorCategory]healthcheck.Scope{
+			healthcheck.CategoryLock: healthcheck.ScopeNone,
+		})
+
+		assert.Equal(t, healthcheck.ScopeNone, scope(healthcheck.CategoryLock))
+		assert.Equal(t, healthcheck.ScopeAll, scope(healthcheck.CategoryDiskFull))
+	})
+}
+
+func TestBuiltinChecks(t *testing.T) {
+	t.Run("should build the checks under their names", func(t *testing.T) {
+		connections, err := healthcheck.NewConnectionCheck(healthcheck.ConnectionThresholds{
+			DegradedPercent:  80,
+			UnhealthyPercent: 95,
+		})
+		require.NoError(t, err)
+
+		transactions, err := healthcheck.NewTransactionCheck(healthcheck.TransactionThresholds{
+			MaxAge:      time.Minute,
+			MaxLockWait: time.Second,
+		}, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, healthcheck.CheckConnections, connections.Name())
+		assert.Equal(t, healthcheck.CheckTransactions, transactions.Name())
+		assert.Equal(t, healthcheck.CheckSemiSync, healthcheck.NewSemiSyncCheck(1).Name())
+	})
+
+	t.Run("should return error for invalid thresholds", func(t *testing.T) {
+		_, err := healthcheck.NewConnectionCheck(healthcheck.ConnectionThresholds{DegradedPercent: 90, UnhealthyPercent: 80})
+		assert.ErrorContains(t, err, "invalid thresholds")
+
+		_, err = healthcheck.NewInnoDBCheck(healthcheck.InnoDBThresholds{})
+		assert.ErrorContains(t, err, "invalid thresholds")
+
+		_, err = healthcheck.NewResourceCheck(healthcheck.ResourceThresholds{})
+		assert.ErrorContains(t, err, "invalid thresholds")
+
+		_, err = healthcheck.NewTransactionCheck(healthcheck.TransactionThresholds{}, false)
+		assert.ErrorContains(t, err, "invalid thresholds")
+	})
+}
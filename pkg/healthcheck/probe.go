@@ -0,0 +1,38 @@
+package healthcheck
+
+import (
+	"net/http"
+)
+
+// Probe identifies which Kubernetes probe issued a request. Callers declare it
+// with the "probe" query parameter, e.g. /health?probe=readiness.
+type Probe string
+
+const (
+	ProbeLiveness  Probe = "liveness"
+	ProbeReadiness Probe = "readiness"
+	ProbeStartup   Probe = "startup"
+	ProbeUnknown   Probe = "unknown"
+)
+
+// ProbeFromRequest returns the probe type declared by r, or ProbeUnknown when
+// the parameter is missing or not recognised.
+func ProbeFromRequest(r *http.Request) Probe {
+	switch p := Probe(r.URL.Query().Get("probe")); p {
+	case ProbeLiveness, ProbeReadiness, ProbeStartup:
+		return p
+	default:
+		return ProbeUnknown
+	}
+}
+
+// Scope returns the check scope a probe is judged by. Only readiness probes
+// fail on readiness-scoped checks; every other probe, including a plain
+// /health, is treated as liveness so MariaDB is not restarted for them.
+func (p Probe) Scope() Scope {
+	if p == ProbeReadiness {
+		return ScopeReadiness
+	}
+
+	return ScopeLiveness
+}
@@ -0,0 +1,85 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// request is what a caller asked for through query parameters.
+type request struct {
+	probe  Probe
+	mode   Mode
+	checks []Checker
+	// picked is set when the request replaced the Checks with ?checks=.
+	picked  bool
+	timeout time.Duration
+	verbose bool
+}
+
+// parseRequest reads the query parameters of a request:
+//
+//   - probe: liveness, readiness or startup
+//   - mode: ping, read or write (default)
+//   - checks: comma-separated checks replacing the Checks; empty for none
+//   - timeout: a duration lowering the timeout
+//   - verbose: return a JSON body
+func (h *Handler) parseRequest(r *http.Request) (request, error) {
+	query := r.URL.Query()
+
+	req := request{
+		probe:   ProbeFromRequest(r),
+		mode:    ModeWrite,
+		checks:  h.options.Checks,
+		timeout: h.options.Timeout,
+	}
+
+	if mode := Mode(query.Get("mode")); mode != "" {
+		if !slices.Contains([]Mode{ModePing, ModeRead, ModeWrite}, mode) {
+			return req, fmt.Errorf("invalid mode %q, available modes: ping, read, write", mode)
+		}
+
+		req.mode = mode
+	}
+
+	if query.Has("checks") {
+		req.checks, req.picked = nil, true
+
+		for name := range strings.SplitSeq(query.Get("checks"), ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+
+			check, ok := h.available[name]
+			if !ok {
+				return req, fmt.Errorf("unknown check %q, available checks: %s", name, h.availableNames())
+			}
+
+			req.checks = append(req.checks, check)
+		}
+	}
+
+	if raw := query.Get("timeout"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return req, fmt.Errorf("invalid timeout %q", raw)
+		}
+
+		// The configured timeout is a ceiling: callers may only ask for less.
+		req.timeout = min(timeout, h.options.Timeout)
+	}
+
+	if raw := query.Get("verbose"); raw != "" {
+		verbose, err := strconv.ParseBool(raw)
+		if err != nil {
+			return req, fmt.Errorf("invalid verbose %q", raw)
+		}
+
+		req.verbose = verbose
+	}
+
+	return req, nil
+}
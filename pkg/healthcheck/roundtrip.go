@@ -0,0 +1,173 @@
+package healthcheck
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/richie-tt/mariadb-healthcheck/internal/mariadb"
+)
+
+// CheckRoundTrip is the name of the INSERT -> SELECT -> DELETE check. With
+// several status tables every table gets its own result, named
+// roundtrip:<table>.
+const CheckRoundTrip = "roundtrip"
+
+// IsRoundTrip reports whether name is the result of a round-trip.
+func IsRoundTrip(name string) bool {
+	return name == CheckRoundTrip || strings.HasPrefix(name, CheckRoundTrip+":")
+}
+
+// RoundTrip is the outcome of the round-trip against one status table.
+type RoundTrip struct {
+	Name   string
+	Scope  Scope
+	Stages []Stage
+	Err    error
+}
+
+// Outcome is everything a single check produced.
+type Outcome struct {
+	Probe Probe
+	// Full reports a write round-trip followed by the default Checks, as
+	// opposed to a request asking for less with ?mode= or ?checks=.
+	Full       bool
+	Start      time.Time
+	Report     Report
+	RoundTrips []RoundTrip
+}
+
+// Err returns the first round-trip error.
+func (o Outcome) Err() error {
+	for _, rt := range o.RoundTrips {
+		if rt.Err != nil {
+			return rt.Err
+		}
+	}
+
+	return nil
+}
+
+// ErrOf returns the error of the named round-trip; nil when name is not a
+// round-trip.
+func (o Outcome) ErrOf(name string) error {
+	for _, rt := range o.RoundTrips {
+		if rt.Name == name {
+			return rt.Err
+		}
+	}
+
+	return nil
+}
+
+// Stages returns the stages of every round-trip. With several tables each
+// stage name is prefixed with its table, e.g. status_aria.insert.
+func (o Outcome) Stages() []Stage {
+	var stages []Stage
+
+	for _, rt := range o.RoundTrips {
+		table, prefixed := strings.CutPrefix(rt.Name, CheckRoundTrip+":")
+
+		for _, stage := range rt.Stages {
+			if prefixed {
+				stage.Name = table + "." + stage.Name
+			}
+
+			stages = append(stages, stage)
+		}
+	}
+
+	return stages
+}
+
+// roundTrip runs the round-trip of the requested depth against every status
+// table, one after the other. A ping does not involve any table and runs once.
+func (h *Handler) roundTrip(ctx context.Context, mode Mode, uuid string) []RoundTrip {
+	db := h.options.DB
+
+	if mode == ModePing {
+		stages, err := mariadb.RunPing(ctx, db)
+
+		return []RoundTrip{{Name: CheckRoundTrip, Scope: ScopeAll, Stages: stages, Err: err}}
+	}
+
+	tables := h.options.Tables
+	result := make([]RoundTrip, 0, len(tables))
+
+	for _, table := range tables {
+		name := CheckRoundTrip
+		if len(tables) > 1 {
+			name += ":" + table.Name
+		}
+
+		var (
+			stages []Stage
+			err    error
+		)
+
+		if mode == ModeRead {
+			stages, err = mariadb.RunRead(ctx, db, table.Name, h.options.StageTimeout)
+		} else {
+			stages, err = mariadb.RunCheck(ctx, db, table.Name, uuid, !h.options.KeepRows, h.options.StageTimeout)
+		}
+
+		result = append(result, RoundTrip{Name: name, Scope: table.Scope, Stages: stages, Err: err})
+	}
+
+	return result
+}
+
+// report builds the report of a check. The gates and checks only run when
+// every round-trip succeeded; otherwise they would just repeat its error.
+func (h *Handler) report(ctx context.Context, checks []Checker, roundTrips []RoundTrip) Report {
+	var report Report
+
+	for _, rt := range roundTrips {
+		roundTrip := Result{
+			Name:    rt.Name,
+			Status:  StatusHealthy,
+			Scope:   rt.Scope,
+			Details: map[string]any{},
+		}
+
+		for _, stage := range rt.Stages {
+			roundTrip.Duration += stage.Duration
+			roundTrip.Details[stage.Name] = stage.Duration
+		}
+
+		if rt.Err != nil {
+			category := mariadb.Classify(rt.Err)
+
+			roundTrip.Status = StatusUnhealthy
+			roundTrip.Scope = h.errorScope(category) & rt.Scope
+			roundTrip.Message = rt.Err.Error()
+			roundTrip.Details["category"] = category
+		}
+
+		report.Results = append(report.Results, roundTrip)
+	}
+
+	if (Outcome{RoundTrips: roundTrips}).Err() != nil {
+		return report
+	}
+
+	for _, check := range h.options.Gates {
+		report.Results = append(report.Results, check.Check(ctx, h.options.DB))
+	}
+
+	for _, check := range checks {
+		report.Results = append(report.Results, check.Check(ctx, h.options.DB))
+	}
+
+	return report
+}
+
+// errorScope returns the probes a round-trip failure of the given category
+// counts against.
+func (h *Handler) errorScope(category ErrorCategory) Scope {
+	if h.options.ErrorScope == nil {
+		return ScopeAll
+	}
+
+	return h.options.ErrorScope(category)
+}
@@ -3,3 +3,8 @@
 -- explicitly — there is no fallback default.
 CREATE USER 'healthcheck'@'127.0.0.1' IDENTIFIED BY 'healthcheck';
 GRANT ALL PRIVILEGES ON `healthcheck`.* TO 'healthcheck'@'127.0.0.1';
+-- Only needed for the /role, /primary and /replica endpoints. Use
+-- REPLICATION CLIENT instead of SLAVE MONITOR before MariaDB 10.5.9.
+-- GRANT SLAVE MONITOR ON *.* TO 'healthcheck'@'127.0.0.1';
+-- Only needed for the innodb and transactions checks.
+-- GRANT PROCESS ON *.* TO 'healthcheck'@'127.0.0.1';